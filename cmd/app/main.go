@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -22,6 +23,8 @@ import (
 	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
 	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
 	"github.com/Zeta-Manu/Backend/internal/adapters/translator"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
 	"github.com/Zeta-Manu/Backend/internal/api/routes"
 	"github.com/Zeta-Manu/Backend/internal/config"
 )
@@ -55,31 +58,86 @@ func main() {
 		log.Fatalf("Failed to connect to AWS Translate: %v", err)
 	}
 
-	mlService, err := httpadapter.NewMLService(appConfig.MLInference.ENDPOINT)
-	if err != nil {
-		log.Fatalf("Failed to connect to ML inference: %v", err)
-	}
-
 	// Create a Gin router
 	r := gin.Default()
+	// Keep at most 8 MiB of each multipart upload in memory; the rest is
+	// spooled to disk and streamed to S3 from there
+	r.MaxMultipartMemory = 8 << 20
 
 	logger, _ := zap.NewProduction()
+
+	// Every backend gets its own retries and circuit breaker, so one failing
+	// model does not hold back the others
+	backends := make([]httpadapter.MLBackend, len(appConfig.MLInference.Backends))
+	for i, backend := range appConfig.MLInference.Backends {
+		mlService, err := newMLService(backend, appConfig.MLInference, creds)
+		if err != nil {
+			log.Fatalf("Invalid configuration of ML backend %s: %v", backend.Name, err)
+		}
+		backends[i] = httpadapter.MLBackend{
+			Name:   backend.Name,
+			Weight: backend.Weight,
+			Service: httpadapter.NewResilientMLService(mlService, httpadapter.RetryPolicy{
+				MaxAttempts: appConfig.MLInference.RetryAttempts,
+				BaseDelay:   appConfig.MLInference.RetryBaseDelay,
+				MaxDelay:    appConfig.MLInference.RetryMaxDelay,
+			}, httpadapter.BreakerOptions{
+				FailureThreshold: appConfig.MLInference.BreakerFailures,
+				OpenTimeout:      appConfig.MLInference.BreakerOpenTimeout,
+			}, logger.With(zap.String("backend", backend.Name))),
+		}
+	}
+	if routing := appConfig.MLInference.Routing; routing != config.MLRoutingWeighted && routing != config.MLRoutingSticky {
+		log.Fatalf("Unknown ML routing %q", routing)
+	}
+	mlRouter, err := httpadapter.NewMLRouter(backends, appConfig.MLInference.Routing == config.MLRoutingSticky)
+	if err != nil {
+		log.Fatalf("Invalid ML backend configuration: %v", err)
+	}
+	if shadow := appConfig.MLInference.ShadowBackend; shadow != "" && !mlRouter.Has(shadow) {
+		log.Fatalf("Shadow ML backend %s is not one of ML_BACKENDS", shadow)
+	}
+
 	r.Use(ginzap.Ginzap(logger, time.RFC3339, true))
 	r.Use(ginzap.RecoveryWithZap(logger, true))
 
 	// CROS-Middleware
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowMethods = []string{"GET", "POST"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Accept-Language", "Idempotency-Key", "X-ML-Backend"}
+	corsConfig.ExposeHeaders = []string{"Idempotent-Replayed", "Retry-After"}
 	r.Use(cors.New(corsConfig))
 
 	// Initialize routes
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "healthy"})
 	})
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	// Dependencies are checked in the background, so the server starts and
+	// keeps serving what it can while one of them is down
+	health := controllers.NewHealthMonitor(appConfig.Health.Interval, appConfig.Health.Timeout, logger)
+	health.Register(controllers.DependencyDatabase, db.Ping)
+	health.Register(controllers.DependencyStorage, s3Adapter.CheckBucket)
+	health.Register(controllers.DependencyTranslate, translateAdapter.CheckHealth)
+	health.Register(controllers.DependencyML, mlRouter.CheckHealth)
+	if len(backends) > 1 {
+		for _, backend := range backends {
+			health.Register(controllers.DependencyML+"/"+backend.Name, backend.Service.CheckHealth)
+		}
+	}
+	health.Start(workerCtx)
+	idempotency := middleware.NewIdempotency(database.NewIdempotencyRepository(db), appConfig.Idempotency.TTL, appConfig.Idempotency.LockTimeout, logger)
+	idempotency.Start(workerCtx)
 	routes.InitTranslateRoutes(r, *translateAdapter)
-	routes.InitPredictRoutes(r, logger, db, *s3Adapter, *translateAdapter, mlService, *appConfig)
+	routes.InitUploadRoutes(r, logger, *s3Adapter, idempotency, *appConfig)
+	routes.InitPredictionRoutes(r, logger, db, *s3Adapter, *appConfig)
+	routes.InitSettingsRoutes(r, logger, db, *appConfig)
+	routes.InitAdminRoutes(r, logger, db, *s3Adapter, health, *appConfig)
+	routes.InitFeedbackRoutes(r, logger, db, *appConfig)
+	webhooks := routes.InitWebhookRoutes(workerCtx, r, logger, db, *appConfig)
+	routes.InitPredictRoutes(workerCtx, r, logger, db, *s3Adapter, *translateAdapter, mlRouter, webhooks, idempotency, health, *appConfig)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
@@ -105,6 +163,7 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server Shutdown:", zap.Error(err))
 	}
+	stopWorkers()
 
 	select {
 	case <-ctx.Done():
@@ -112,3 +171,26 @@ func main() {
 	}
 	logger.Info("Server exiting")
 }
+
+// newMLService returns the client of an ML backend.
+func newMLService(backend config.MLBackendConfig, cfg config.MLInferenceConfig, creds *credentials.Credentials) (httpadapter.MLService, error) {
+	options := httpadapter.MLClientOptions{
+		RequestTimeout: cfg.Timeout,
+		ConnectTimeout: cfg.ConnectTimeout,
+		HealthTimeout:  cfg.HealthTimeout,
+		MaxIdleConns:   cfg.MaxIdleConns,
+	}
+
+	switch backend.Type {
+	case config.MLBackendHTTP:
+		return httpadapter.NewMLService(backend.ENDPOINT, options)
+	case config.MLBackendSageMaker:
+		return httpadapter.NewSageMakerMLService(httpadapter.SageMakerOptions{
+			Endpoint:    backend.SageMaker.ENDPOINT,
+			Region:      backend.SageMaker.Region,
+			EndpointURL: backend.SageMaker.EndpointURL,
+		}, creds, options)
+	default:
+		return nil, fmt.Errorf("unknown ML backend type %q", backend.Type)
+	}
+}
@@ -0,0 +1,95 @@
+// Command export writes a training dataset manifest of confirmed and
+// corrected predictions to the bucket, like POST /api/admin/exports, and
+// prints where it went. It reads the same environment as the API server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	"github.com/Zeta-Manu/Backend/internal/dataset"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+func main() {
+	from := flag.String("from", "", "only feedback given at or after this time (RFC 3339 or YYYY-MM-DD)")
+	to := flag.String("to", "", "only feedback given before this time (RFC 3339 or YYYY-MM-DD)")
+	classes := flag.String("class", "", "only examples with these labels, comma separated")
+	sources := flag.String("source", "", "only examples with these label sources, comma separated: confirmed, corrected")
+	includeUnconsented := flag.Bool("include-unconsented", false, "also export examples of users who did not consent")
+	flag.Parse()
+
+	filter := entity.DatasetFilter{IncludeUnconsented: *includeUnconsented}
+	if *from != "" {
+		t, err := validators.ParseTime(*from)
+		if err != nil {
+			log.Fatalf("-from %v", err)
+		}
+		filter.From = &t
+	}
+	if *to != "" {
+		t, err := validators.ParseTime(*to)
+		if err != nil {
+			log.Fatalf("-to %v", err)
+		}
+		filter.To = &t
+	}
+	filter.Classes = splitList(*classes)
+	for _, source := range splitList(*sources) {
+		if source != string(entity.LabelConfirmed) && source != string(entity.LabelCorrected) {
+			log.Fatalf("-source must be confirmed or corrected, got %q", source)
+		}
+		filter.Sources = append(filter.Sources, entity.LabelSource(source))
+	}
+
+	appConfig := config.NewAppConfig()
+	creds := credentials.NewStaticCredentials(appConfig.IAM.Key, appConfig.IAM.Secret, "")
+
+	db, err := database.InitializeDatabase(appConfig.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+	defer db.Close()
+
+	s3Adapter, err := s3.NewS3Adapter(appConfig.S3.Region, appConfig.S3.BucketName, creds)
+	if err != nil {
+		log.Fatalf("Failed to connect to S3: %v", err)
+	}
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	exporter := dataset.NewExporter(*s3Adapter, database.NewFeedbackRepository(db), logger)
+	export, err := exporter.Export(context.Background(), filter, "cli")
+	if err != nil {
+		log.Fatalf("Failed to export dataset: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		log.Fatalf("Failed to print export: %v", err)
+	}
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
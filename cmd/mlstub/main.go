@@ -0,0 +1,155 @@
+// Command mlstub is a stand-in for the ML inference service for local
+// development. It answers /healthz, /predict and /stream/predict with
+// made-up but well-formed results, so the API, including live streams, can
+// be exercised without a model. It also stands in for a SageMaker endpoint
+// when SAGEMAKER_ENDPOINT_URL points at it.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// classes are the signs the stub pretends to recognise.
+var classes = []string{"hello", "thank_you", "please", "yes", "no", "sorry", "good_morning"}
+
+// maxStreamRequestBytes bounds the size of a stream window.
+const maxStreamRequestBytes = 32 << 20
+
+func main() {
+	addr := flag.String("addr", ":8000", "address to listen on")
+	fps := flag.Float64("fps", 15, "frame rate reported with every result")
+	frames := flag.Int("frames", 60, "number of frames returned for a /predict call")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/predict", func(w http.ResponseWriter, r *http.Request) {
+		uri := r.URL.Query().Get("s3_uri")
+		if uri == "" {
+			http.Error(w, "s3_uri is required", http.StatusBadRequest)
+			return
+		}
+		writeResult(w, videoFrames(uri, *frames), *fps)
+	})
+
+	// SageMaker runtime: the payload carries the URI the HTTP backend gets
+	// as a query parameter
+	mux.HandleFunc("/endpoints/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/invocations") {
+			http.NotFound(w, r)
+			return
+		}
+		var payload struct {
+			S3URI string `json:"s3_uri"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.S3URI == "" {
+			writeModelError(w, http.StatusBadRequest, "s3_uri is required")
+			return
+		}
+		writeResult(w, videoFrames(payload.S3URI, *frames), *fps)
+	})
+
+	// Each window of a session is recognised as the next sign in the list
+	mux.HandleFunc("/stream/predict", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxStreamRequestBytes)
+		if err := r.ParseMultipartForm(maxStreamRequestBytes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		session := r.FormValue("session")
+		seq, err := strconv.Atoi(r.FormValue("seq"))
+		if session == "" || err != nil {
+			http.Error(w, "session and seq are required", http.StatusBadRequest)
+			return
+		}
+		parts := r.MultipartForm.File["data"]
+		if len(parts) == 0 {
+			http.Error(w, "no data parts", http.StatusBadRequest)
+			return
+		}
+
+		seed := hash(session)
+		sign := (seed + uint32(seq)) % uint32(len(classes))
+		raw := make([]valueobjects.MlFrame, len(parts))
+		for i := range raw {
+			raw[i] = valueobjects.MlFrame{Class: classes[sign], Conf: confidence(seed, seq+i)}
+		}
+		log.Printf("stream %s window %d: %d parts, %s", session, seq, len(parts), classes[sign])
+		writeResult(w, raw, *fps)
+	})
+
+	log.Printf("ML stub listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// videoFrames returns a few signs in a row, chosen from uri so the same video
+// always gets the same result.
+func videoFrames(uri string, frames int) []valueobjects.MlFrame {
+	seed := hash(uri)
+	raw := make([]valueobjects.MlFrame, frames)
+	for i := range raw {
+		sign := (seed + uint32(i/20)) % uint32(len(classes))
+		raw[i] = valueobjects.MlFrame{Class: classes[sign], Conf: confidence(seed, i)}
+	}
+	return raw
+}
+
+// writeModelError responds like SageMaker does when the model container
+// answered with status.
+func writeModelError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-ErrorType", "ModelError")
+	w.WriteHeader(http.StatusFailedDependency)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"__type":             "ModelError",
+		"message":            "Received client error (" + strconv.Itoa(status) + ") from model",
+		"OriginalStatusCode": status,
+		"OriginalMessage":    message,
+	})
+}
+
+// writeResult responds with raw and the per-class averages computed from it.
+func writeResult(w http.ResponseWriter, raw []valueobjects.MlFrame, fps float64) {
+	var response valueobjects.MlResponse
+	response.Results.Raw = raw
+	response.Results.FPS = fps
+	response.Results.Avg = make(map[string]valueobjects.MlAverage)
+	for _, frame := range raw {
+		avg := response.Results.Avg[frame.Class]
+		avg.Sum += frame.Conf
+		avg.Count++
+		avg.Average = avg.Sum / float64(avg.Count)
+		response.Results.Avg[frame.Class] = avg
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
+
+// confidence returns a repeatable confidence between 0.6 and 1.
+func confidence(seed uint32, i int) float64 {
+	return 0.6 + float64((seed+uint32(i)*7919)%400)/1000
+}
+
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
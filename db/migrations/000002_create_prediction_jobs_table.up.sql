@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS prediction_jobs (
+ id CHAR(36) PRIMARY KEY,
+ sub VARCHAR(255) NOT NULL,
+ s3_link VARCHAR(1024) NOT NULL,
+ status VARCHAR(16) NOT NULL DEFAULT 'queued',
+ result JSON DEFAULT NULL,
+ error TEXT DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+ INDEX idx_prediction_jobs_sub (sub),
+ INDEX idx_prediction_jobs_status (status, updated_at)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE prediction_jobs DROP COLUMN content_hash;
+
+DROP TABLE IF EXISTS prediction_cache;
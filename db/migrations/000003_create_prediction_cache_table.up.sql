@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS prediction_cache (
+ content_hash CHAR(64) NOT NULL,
+ model VARCHAR(128) NOT NULL,
+ ml_response JSON NOT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ PRIMARY KEY (content_hash, model)
+);
+
+ALTER TABLE prediction_jobs ADD COLUMN content_hash CHAR(64) DEFAULT NULL AFTER s3_link;
@@ -0,0 +1,5 @@
+ALTER TABLE prediction_jobs
+ DROP COLUMN prediction_id,
+ DROP COLUMN video_key;
+
+DROP TABLE IF EXISTS predictions;
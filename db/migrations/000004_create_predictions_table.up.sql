@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS predictions (
+ id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+ sub VARCHAR(255) NOT NULL,
+ video_key VARCHAR(1024) NOT NULL,
+ content_hash CHAR(64) DEFAULT NULL,
+ model VARCHAR(128) NOT NULL,
+ status VARCHAR(16) NOT NULL,
+ cached BOOLEAN NOT NULL DEFAULT FALSE,
+ raw JSON DEFAULT NULL,
+ avg JSON DEFAULT NULL,
+ result JSON DEFAULT NULL,
+ timings JSON DEFAULT NULL,
+ error TEXT DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ INDEX idx_predictions_sub (sub, id),
+ INDEX idx_predictions_content_hash (content_hash)
+);
+
+ALTER TABLE prediction_jobs
+ ADD COLUMN video_key VARCHAR(1024) DEFAULT NULL AFTER sub,
+ ADD COLUMN prediction_id BIGINT UNSIGNED DEFAULT NULL AFTER content_hash;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+ id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+ sub VARCHAR(255) NOT NULL,
+ action VARCHAR(64) NOT NULL,
+ target VARCHAR(255) DEFAULT NULL,
+ detail JSON DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ INDEX idx_audit_log_sub (sub, created_at)
+);
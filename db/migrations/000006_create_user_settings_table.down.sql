@@ -0,0 +1,3 @@
+ALTER TABLE prediction_jobs DROP COLUMN languages;
+
+DROP TABLE IF EXISTS user_settings;
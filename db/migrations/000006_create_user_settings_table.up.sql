@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_settings (
+ sub VARCHAR(255) NOT NULL PRIMARY KEY,
+ default_language VARCHAR(16) DEFAULT NULL,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE prediction_jobs ADD COLUMN languages JSON DEFAULT NULL AFTER content_hash;
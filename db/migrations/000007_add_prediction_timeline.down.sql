@@ -0,0 +1,3 @@
+ALTER TABLE prediction_jobs DROP COLUMN timeline;
+
+ALTER TABLE predictions DROP COLUMN timeline;
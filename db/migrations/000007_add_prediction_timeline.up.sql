@@ -0,0 +1,3 @@
+ALTER TABLE predictions ADD COLUMN timeline JSON DEFAULT NULL AFTER result;
+
+ALTER TABLE prediction_jobs ADD COLUMN timeline JSON DEFAULT NULL AFTER result;
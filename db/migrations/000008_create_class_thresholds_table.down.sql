@@ -0,0 +1,3 @@
+ALTER TABLE prediction_jobs DROP COLUMN result_options;
+
+DROP TABLE IF EXISTS class_thresholds;
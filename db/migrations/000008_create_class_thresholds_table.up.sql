@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS class_thresholds (
+ class VARCHAR(255) NOT NULL PRIMARY KEY,
+ min_average DOUBLE DEFAULT NULL,
+ min_count INT UNSIGNED DEFAULT NULL,
+ updated_by VARCHAR(255) NOT NULL,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE prediction_jobs ADD COLUMN result_options JSON DEFAULT NULL AFTER languages;
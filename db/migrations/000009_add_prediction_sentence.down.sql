@@ -0,0 +1,3 @@
+ALTER TABLE prediction_jobs DROP COLUMN sentence;
+
+ALTER TABLE predictions DROP COLUMN sentence;
@@ -0,0 +1,3 @@
+ALTER TABLE predictions ADD COLUMN sentence JSON DEFAULT NULL AFTER timeline;
+
+ALTER TABLE prediction_jobs ADD COLUMN sentence JSON DEFAULT NULL AFTER timeline;
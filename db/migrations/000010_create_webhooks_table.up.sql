@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+ id CHAR(36) NOT NULL PRIMARY KEY,
+ sub VARCHAR(255) NOT NULL,
+ url VARCHAR(2048) NOT NULL,
+ secret VARCHAR(128) NOT NULL,
+ events JSON NOT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ INDEX idx_webhooks_sub (sub)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+ id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+ webhook_id CHAR(36) NOT NULL,
+ event VARCHAR(64) NOT NULL,
+ payload JSON NOT NULL,
+ status VARCHAR(16) NOT NULL,
+ attempts INT UNSIGNED NOT NULL DEFAULT 0,
+ response_status INT DEFAULT NULL,
+ error TEXT DEFAULT NULL,
+ next_attempt_at TIMESTAMP NULL DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+ INDEX idx_webhook_deliveries_webhook (webhook_id, id),
+ INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+ CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
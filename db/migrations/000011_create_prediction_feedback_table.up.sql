@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS prediction_feedback (
+ id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+ prediction_id BIGINT UNSIGNED NOT NULL,
+ sub VARCHAR(255) NOT NULL,
+ video_key VARCHAR(1024) NOT NULL,
+ model VARCHAR(128) NOT NULL,
+ rating VARCHAR(8) NOT NULL,
+ predicted_class VARCHAR(255) DEFAULT NULL,
+ correct_class VARCHAR(255) DEFAULT NULL,
+ start_seconds DOUBLE DEFAULT NULL,
+ end_seconds DOUBLE DEFAULT NULL,
+ note TEXT DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ INDEX idx_prediction_feedback_prediction (prediction_id),
+ CONSTRAINT fk_prediction_feedback_prediction FOREIGN KEY (prediction_id) REFERENCES predictions (id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP INDEX idx_prediction_feedback_created_at ON prediction_feedback;
+
+ALTER TABLE user_settings DROP COLUMN training_consent;
@@ -0,0 +1,3 @@
+ALTER TABLE user_settings ADD COLUMN training_consent BOOLEAN NOT NULL DEFAULT FALSE AFTER default_language;
+
+CREATE INDEX idx_prediction_feedback_created_at ON prediction_feedback (created_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+ sub VARCHAR(255) NOT NULL,
+ idempotency_key VARCHAR(255) NOT NULL,
+ fingerprint CHAR(64) NOT NULL,
+ status VARCHAR(16) NOT NULL,
+ response_status INT DEFAULT NULL,
+ content_type VARCHAR(255) DEFAULT NULL,
+ response_body MEDIUMBLOB DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+ PRIMARY KEY (sub, idempotency_key),
+ INDEX idx_idempotency_keys_created_at (created_at)
+);
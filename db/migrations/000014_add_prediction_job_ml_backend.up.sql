@@ -0,0 +1 @@
+ALTER TABLE prediction_jobs ADD COLUMN ml_backend VARCHAR(128) NULL AFTER result_options;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS shadow_prediction_classes;
+DROP TABLE IF EXISTS shadow_predictions;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS shadow_predictions (
+ id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+ prediction_id BIGINT UNSIGNED NOT NULL,
+ primary_model VARCHAR(128) NOT NULL,
+ shadow_model VARCHAR(128) NOT NULL,
+ primary_class VARCHAR(255) DEFAULT NULL,
+ shadow_class VARCHAR(255) DEFAULT NULL,
+ agree BOOLEAN NOT NULL DEFAULT FALSE,
+ primary_avg JSON DEFAULT NULL,
+ shadow_avg JSON DEFAULT NULL,
+ inference_ms BIGINT NOT NULL DEFAULT 0,
+ error TEXT DEFAULT NULL,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ INDEX idx_shadow_predictions_model (shadow_model, created_at),
+ CONSTRAINT fk_shadow_predictions_prediction FOREIGN KEY (prediction_id) REFERENCES predictions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS shadow_prediction_classes (
+ shadow_prediction_id BIGINT UNSIGNED NOT NULL,
+ class VARCHAR(255) NOT NULL,
+ primary_average DOUBLE NOT NULL,
+ shadow_average DOUBLE NOT NULL,
+ PRIMARY KEY (shadow_prediction_id, class),
+ CONSTRAINT fk_shadow_prediction_classes_shadow FOREIGN KEY (shadow_prediction_id) REFERENCES shadow_predictions (id) ON DELETE CASCADE
+);
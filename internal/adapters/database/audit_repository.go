@@ -0,0 +1,32 @@
+package database
+
+import (
+	"encoding/json"
+)
+
+// Audit actions recorded in the audit log.
+const (
+	AuditDeletePrediction = "prediction.delete"
+	AuditDeleteAllVideos  = "videos.delete_all"
+	AuditPutThreshold     = "class_threshold.put"
+	AuditDeleteThreshold  = "class_threshold.delete"
+	AuditExportDataset    = "dataset.export"
+)
+
+type AuditRepository struct {
+	db DBAdapter
+}
+
+func NewAuditRepository(db DBAdapter) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record appends an entry to the audit log. detail is stored as JSON.
+func (r *AuditRepository) Record(sub string, action string, target string, detail interface{}) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("INSERT INTO audit_log (sub, action, target, detail) VALUES (?, ?, NULLIF(?, ''), ?);", sub, action, target, data)
+	return err
+}
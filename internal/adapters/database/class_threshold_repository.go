@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type ClassThresholdRepository struct {
+	db DBAdapter
+}
+
+func NewClassThresholdRepository(db DBAdapter) *ClassThresholdRepository {
+	return &ClassThresholdRepository{db: db}
+}
+
+// List returns every class override, ordered by class.
+func (r *ClassThresholdRepository) List() ([]entity.ClassThreshold, error) {
+	rows, err := r.db.Query("SELECT class, min_average, min_count, updated_by, updated_at FROM class_thresholds ORDER BY class;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	thresholds := []entity.ClassThreshold{}
+	for rows.Next() {
+		var (
+			t          entity.ClassThreshold
+			minAverage sql.NullFloat64
+			minCount   sql.NullInt64
+		)
+		if err := rows.Scan(&t.Class, &minAverage, &minCount, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if minAverage.Valid {
+			t.MinAverage = &minAverage.Float64
+		}
+		if minCount.Valid {
+			count := int(minCount.Int64)
+			t.MinCount = &count
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, rows.Err()
+}
+
+// ByClass returns every class override keyed by class.
+func (r *ClassThresholdRepository) ByClass() (map[string]entity.ClassThreshold, error) {
+	thresholds, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byClass := make(map[string]entity.ClassThreshold, len(thresholds))
+	for _, t := range thresholds {
+		byClass[t.Class] = t
+	}
+	return byClass, nil
+}
+
+// Put stores or replaces the override of a class.
+func (r *ClassThresholdRepository) Put(t *entity.ClassThreshold) error {
+	query := "INSERT INTO class_thresholds (class, min_average, min_count, updated_by) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE min_average = VALUES(min_average), min_count = VALUES(min_count), updated_by = VALUES(updated_by);"
+	_, err := r.db.Exec(query, t.Class, t.MinAverage, t.MinCount, t.UpdatedBy)
+	return err
+}
+
+// Delete removes the override of a class. It returns ErrNotFound if there was none.
+func (r *ClassThresholdRepository) Delete(class string) error {
+	result, err := r.db.Exec("DELETE FROM class_thresholds WHERE class = ?;", class)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
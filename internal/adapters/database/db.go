@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -31,7 +32,7 @@ func NewDatabase(dataSourceName string) (*Database, error) {
 // InitializeDatabase initializes and returns a new database connection.
 func InitializeDatabase(dbConfig config.DatabaseConfig) (*Database, error) {
 	dbDataSourceName := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s",
+		"%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		dbConfig.User,
 		dbConfig.Password,
 		dbConfig.Host,
@@ -48,3 +49,8 @@ func (db *Database) Close() error {
 	}
 	return nil
 }
+
+// Ping checks that the database can still be reached.
+func (db *Database) Ping(ctx context.Context) error {
+	return db.Conn.PingContext(ctx)
+}
@@ -6,6 +6,7 @@ import (
 
 type DBAdapter interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 	Exec(query string, args ...interface{}) (sql.Result, error)
 }
 
@@ -18,6 +19,11 @@ func (db *Database) Query(query string, args ...interface{}) (*sql.Rows, error)
 	return rows, nil
 }
 
+// Executes a SQL query that is expected to return at most one row
+func (db *Database) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.Conn.QueryRow(query, args...)
+}
+
 // Execute a SQL statement
 func (db *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
 	result, err := db.Conn.Exec(query, args...)
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// feedbackLabel and feedbackLabelSource derive the training label of a piece
+// of feedback: the corrected class if there is one, else the predicted class.
+const (
+	feedbackLabel       = "COALESCE(f.correct_class, f.predicted_class)"
+	feedbackLabelSource = "CASE WHEN f.correct_class IS NULL OR f.correct_class <=> f.predicted_class THEN 'confirmed' ELSE 'corrected' END"
+)
+
+type FeedbackRepository struct {
+	db DBAdapter
+}
+
+func NewFeedbackRepository(db DBAdapter) *FeedbackRepository {
+	return &FeedbackRepository{db: db}
+}
+
+// Create stores feedback and fills in its ID.
+func (r *FeedbackRepository) Create(f *entity.Feedback) error {
+	query := "INSERT INTO prediction_feedback (prediction_id, sub, video_key, model, rating, predicted_class, correct_class, start_seconds, end_seconds, note) VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, NULLIF(?, ''));"
+	res, err := r.db.Exec(query, f.PredictionID, f.Sub, f.VideoKey, f.Model, f.Rating, f.PredictedClass, f.CorrectClass, f.StartSeconds, f.EndSeconds, f.Note)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	f.ID = id
+	f.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+// List returns the feedback matching filter, oldest first, so consumers can
+// follow new feedback by passing the last ID they saw as After.
+func (r *FeedbackRepository) List(filter entity.FeedbackFilter) ([]entity.Feedback, error) {
+	query := "SELECT id, prediction_id, sub, video_key, model, rating, predicted_class, correct_class, start_seconds, end_seconds, note, created_at FROM prediction_feedback WHERE id > ?"
+	args := []interface{}{filter.After}
+	if filter.Rating != "" {
+		query += " AND rating = ?"
+		args = append(args, filter.Rating)
+	}
+	query += " ORDER BY id LIMIT ?;"
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := []entity.Feedback{}
+	for rows.Next() {
+		var (
+			f              entity.Feedback
+			predictedClass sql.NullString
+			correctClass   sql.NullString
+			startSeconds   sql.NullFloat64
+			endSeconds     sql.NullFloat64
+			note           sql.NullString
+		)
+		if err := rows.Scan(&f.ID, &f.PredictionID, &f.Sub, &f.VideoKey, &f.Model, &f.Rating, &predictedClass, &correctClass, &startSeconds, &endSeconds, &note, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		f.PredictedClass = predictedClass.String
+		f.CorrectClass = correctClass.String
+		if startSeconds.Valid {
+			f.StartSeconds = &startSeconds.Float64
+		}
+		if endSeconds.Valid {
+			f.EndSeconds = &endSeconds.Float64
+		}
+		f.Note = note.String
+		feedback = append(feedback, f)
+	}
+	return feedback, rows.Err()
+}
+
+// TrainingExamples returns the feedback matching filter that yields a label,
+// i.e. a correction or a thumbs up on a predicted class, oldest first.
+func (r *FeedbackRepository) TrainingExamples(filter entity.DatasetFilter) ([]entity.TrainingExample, error) {
+	query := "SELECT f.id, f.prediction_id, f.video_key, f.model, " + feedbackLabel + ", " + feedbackLabelSource + ", f.predicted_class, f.start_seconds, f.end_seconds, COALESCE(s.training_consent, FALSE), f.created_at FROM prediction_feedback f LEFT JOIN user_settings s ON s.sub = f.sub WHERE (f.correct_class IS NOT NULL OR (f.rating = ? AND f.predicted_class IS NOT NULL))"
+	args := []interface{}{entity.FeedbackUp}
+
+	if !filter.IncludeUnconsented {
+		query += " AND s.training_consent = TRUE"
+	}
+	if filter.From != nil {
+		query += " AND f.created_at >= ?"
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += " AND f.created_at < ?"
+		args = append(args, filter.To.UTC())
+	}
+	if len(filter.Classes) > 0 {
+		query += " AND " + feedbackLabel + " IN (?" + strings.Repeat(", ?", len(filter.Classes)-1) + ")"
+		for _, class := range filter.Classes {
+			args = append(args, class)
+		}
+	}
+	if len(filter.Sources) > 0 {
+		query += " AND " + feedbackLabelSource + " IN (?" + strings.Repeat(", ?", len(filter.Sources)-1) + ")"
+		for _, source := range filter.Sources {
+			args = append(args, source)
+		}
+	}
+	query += " ORDER BY f.id;"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	examples := []entity.TrainingExample{}
+	for rows.Next() {
+		var (
+			e              entity.TrainingExample
+			predictedClass sql.NullString
+			startSeconds   sql.NullFloat64
+			endSeconds     sql.NullFloat64
+		)
+		if err := rows.Scan(&e.FeedbackID, &e.PredictionID, &e.VideoKey, &e.Model, &e.Label, &e.LabelSource, &predictedClass, &startSeconds, &endSeconds, &e.Consent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.PredictedClass = predictedClass.String
+		if startSeconds.Valid {
+			e.StartSeconds = &startSeconds.Float64
+		}
+		if endSeconds.Valid {
+			e.EndSeconds = &endSeconds.Float64
+		}
+		examples = append(examples, e)
+	}
+	return examples, rows.Err()
+}
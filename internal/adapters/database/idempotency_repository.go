@@ -0,0 +1,95 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type IdempotencyRepository struct {
+	db DBAdapter
+}
+
+func NewIdempotencyRepository(db DBAdapter) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Begin records that a request with key is in flight. If the key is already
+// taken it returns the existing record and false instead. Records older than
+// ttl, and in-flight records not finished within lockTimeout, e.g. because
+// the process died, no longer count and are replaced.
+func (r *IdempotencyRepository) Begin(sub string, key string, fingerprint string, ttl time.Duration, lockTimeout time.Duration) (*entity.IdempotencyRecord, bool, error) {
+	now := time.Now().UTC()
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE sub = ? AND idempotency_key = ? AND (created_at < ? OR (status = ? AND updated_at < ?));", sub, key, now.Add(-ttl), entity.IdempotencyInFlight, now.Add(-lockTimeout))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Only one of several concurrent requests with the same key gets the row
+	result, err := r.db.Exec("INSERT IGNORE INTO idempotency_keys (sub, idempotency_key, fingerprint, status) VALUES (?, ?, ?, ?);", sub, key, fingerprint, entity.IdempotencyInFlight)
+	if err != nil {
+		return nil, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if affected == 1 {
+		return &entity.IdempotencyRecord{Sub: sub, Key: key, Fingerprint: fingerprint, Status: entity.IdempotencyInFlight, CreatedAt: now, UpdatedAt: now}, true, nil
+	}
+
+	record, err := r.get(sub, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+// Complete stores the response of the request holding key.
+func (r *IdempotencyRepository) Complete(sub string, key string, status int, contentType string, body []byte) error {
+	_, err := r.db.Exec("UPDATE idempotency_keys SET status = ?, response_status = ?, content_type = NULLIF(?, ''), response_body = ? WHERE sub = ? AND idempotency_key = ?;", entity.IdempotencyCompleted, status, contentType, body, sub, key)
+	return err
+}
+
+// Release forgets key so the request can be retried with it.
+func (r *IdempotencyRepository) Release(sub string, key string) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE sub = ? AND idempotency_key = ?;", sub, key)
+	return err
+}
+
+// DeleteBySub removes every record of sub.
+func (r *IdempotencyRepository) DeleteBySub(sub string) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE sub = ?;", sub)
+	return err
+}
+
+// DeleteExpired removes every record created before cutoff.
+func (r *IdempotencyRepository) DeleteExpired(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE created_at < ?;", cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *IdempotencyRepository) get(sub string, key string) (*entity.IdempotencyRecord, error) {
+	var (
+		record         entity.IdempotencyRecord
+		responseStatus sql.NullInt64
+		contentType    sql.NullString
+	)
+	query := "SELECT sub, idempotency_key, fingerprint, status, response_status, content_type, response_body, created_at, updated_at FROM idempotency_keys WHERE sub = ? AND idempotency_key = ?;"
+	err := r.db.QueryRow(query, sub, key).Scan(&record.Sub, &record.Key, &record.Fingerprint, &record.Status, &responseStatus, &contentType, &record.ResponseBody, &record.CreatedAt, &record.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.ResponseStatus = int(responseStatus.Int64)
+	record.ContentType = contentType.String
+	return &record, nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// PredictionCacheRepository stores raw ML responses by video content hash and
+// model, so identical videos are only sent to the ML service once per model.
+type PredictionCacheRepository struct {
+	db DBAdapter
+}
+
+func NewPredictionCacheRepository(db DBAdapter) *PredictionCacheRepository {
+	return &PredictionCacheRepository{db: db}
+}
+
+// Get returns the cached ML response, or ErrNotFound.
+func (r *PredictionCacheRepository) Get(contentHash string, model string) ([]byte, error) {
+	var data []byte
+	err := r.db.QueryRow("SELECT ml_response FROM prediction_cache WHERE content_hash = ? AND model = ?;", contentHash, model).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Put stores or replaces the ML response for a content hash and model.
+func (r *PredictionCacheRepository) Put(contentHash string, model string, data []byte) error {
+	query := "INSERT INTO prediction_cache (content_hash, model, ml_response) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE ml_response = VALUES(ml_response), created_at = CURRENT_TIMESTAMP;"
+	_, err := r.db.Exec(query, contentHash, model, data)
+	return err
+}
+
+// Delete removes the cached responses of the given content hashes for every model.
+func (r *PredictionCacheRepository) Delete(contentHashes ...string) error {
+	if len(contentHashes) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(contentHashes))
+	for i, hash := range contentHashes {
+		args[i] = hash
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(contentHashes)), ", ")
+	_, err := r.db.Exec("DELETE FROM prediction_cache WHERE content_hash IN ("+placeholders+");", args...)
+	return err
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+type PredictionJobRepository struct {
+	db DBAdapter
+}
+
+func NewPredictionJobRepository(db DBAdapter) *PredictionJobRepository {
+	return &PredictionJobRepository{db: db}
+}
+
+// Create inserts a new queued job and fills in its ID.
+func (r *PredictionJobRepository) Create(job *entity.PredictionJob) error {
+	id, err := NewID()
+	if err != nil {
+		return err
+	}
+
+	languages, err := json.Marshal(job.Languages)
+	if err != nil {
+		return err
+	}
+	options, err := json.Marshal(job.Options)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO prediction_jobs (id, sub, video_key, s3_link, content_hash, languages, result_options, ml_backend, status) VALUES (?, ?, ?, ?, NULLIF(?, ''), ?, ?, NULLIF(?, ''), ?);"
+	if _, err := r.db.Exec(query, id, job.Sub, job.VideoKey, job.S3Link, job.ContentHash, languages, options, job.MLBackend, entity.PredictionJobQueued); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	job.ID = id
+	job.Status = entity.PredictionJobQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	return nil
+}
+
+// Get returns the job with the given ID owned by sub.
+func (r *PredictionJobRepository) Get(id string, sub string) (*entity.PredictionJob, error) {
+	query := "SELECT id, sub, video_key, s3_link, content_hash, languages, result_options, ml_backend, prediction_id, status, result, timeline, sentence, error, created_at, updated_at FROM prediction_jobs WHERE id = ? AND sub = ?;"
+	return r.scan(r.db.QueryRow(query, id, sub))
+}
+
+// Claim moves a queued job to running. It returns false if another worker
+// already took the job or the job is no longer queued.
+func (r *PredictionJobRepository) Claim(id string) (*entity.PredictionJob, bool, error) {
+	result, err := r.db.Exec("UPDATE prediction_jobs SET status = ? WHERE id = ? AND status = ?;", entity.PredictionJobRunning, id, entity.PredictionJobQueued)
+	if err != nil {
+		return nil, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if affected == 0 {
+		return nil, false, nil
+	}
+
+	query := "SELECT id, sub, video_key, s3_link, content_hash, languages, result_options, ml_backend, prediction_id, status, result, timeline, sentence, error, created_at, updated_at FROM prediction_jobs WHERE id = ?;"
+	job, err := r.scan(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, false, err
+	}
+	return job, true, nil
+}
+
+// MarkSucceeded stores the final result, timeline and sentence of a job, the
+// prediction it produced and, as its ML backend, the model that produced it.
+func (r *PredictionJobRepository) MarkSucceeded(id string, prediction *entity.Prediction) error {
+	result, err := json.Marshal(prediction.Result)
+	if err != nil {
+		return err
+	}
+	timeline, err := json.Marshal(prediction.Timeline)
+	if err != nil {
+		return err
+	}
+	sentence, err := json.Marshal(prediction.Sentence)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("UPDATE prediction_jobs SET status = ?, prediction_id = NULLIF(?, 0), ml_backend = NULLIF(?, ''), result = ?, timeline = ?, sentence = ?, error = NULL WHERE id = ?;", entity.PredictionJobSucceeded, prediction.ID, prediction.Model, result, timeline, sentence, id)
+	return err
+}
+
+// MarkFailed stores the error message of a job. predictionID is zero when the
+// job failed before a prediction was recorded.
+func (r *PredictionJobRepository) MarkFailed(id string, predictionID int64, message string) error {
+	_, err := r.db.Exec("UPDATE prediction_jobs SET status = ?, prediction_id = NULLIF(?, 0), error = ? WHERE id = ?;", entity.PredictionJobFailed, predictionID, message, id)
+	return err
+}
+
+// Requeue puts a running job back into the queue.
+func (r *PredictionJobRepository) Requeue(id string) error {
+	_, err := r.db.Exec("UPDATE prediction_jobs SET status = ? WHERE id = ? AND status = ?;", entity.PredictionJobQueued, id, entity.PredictionJobRunning)
+	return err
+}
+
+// RequeueStale puts jobs that have been running for longer than olderThan
+// back into the queue, e.g. after their worker was killed mid-job, and
+// returns their IDs.
+func (r *PredictionJobRepository) RequeueStale(olderThan time.Duration) ([]string, error) {
+	cutoff := time.Now().UTC().Add(-olderThan)
+	rows, err := r.db.Query("SELECT id FROM prediction_jobs WHERE status = ? AND updated_at < ?;", entity.PredictionJobRunning, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stale = append(stale, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, id := range stale {
+		// The job may have finished since it was listed
+		result, err := r.db.Exec("UPDATE prediction_jobs SET status = ? WHERE id = ? AND status = ? AND updated_at < ?;", entity.PredictionJobQueued, id, entity.PredictionJobRunning, cutoff)
+		if err != nil {
+			return ids, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return ids, err
+		}
+		if affected == 1 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ListQueuedIDs returns the IDs of all queued jobs, oldest first.
+func (r *PredictionJobRepository) ListQueuedIDs() ([]string, error) {
+	rows, err := r.db.Query("SELECT id FROM prediction_jobs WHERE status = ? ORDER BY created_at;", entity.PredictionJobQueued)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteByPrediction removes the jobs that produced a prediction.
+func (r *PredictionJobRepository) DeleteByPrediction(predictionID int64) error {
+	_, err := r.db.Exec("DELETE FROM prediction_jobs WHERE prediction_id = ?;", predictionID)
+	return err
+}
+
+// DeleteBySub removes every job of sub.
+func (r *PredictionJobRepository) DeleteBySub(sub string) error {
+	_, err := r.db.Exec("DELETE FROM prediction_jobs WHERE sub = ?;", sub)
+	return err
+}
+
+func (r *PredictionJobRepository) scan(row *sql.Row) (*entity.PredictionJob, error) {
+	var (
+		job          entity.PredictionJob
+		videoKey     sql.NullString
+		contentHash  sql.NullString
+		languages    []byte
+		options      []byte
+		mlBackend    sql.NullString
+		predictionID sql.NullInt64
+		result       []byte
+		timeline     []byte
+		sentence     []byte
+		message      sql.NullString
+	)
+	err := row.Scan(&job.ID, &job.Sub, &videoKey, &job.S3Link, &contentHash, &languages, &options, &mlBackend, &predictionID, &job.Status, &result, &timeline, &sentence, &message, &job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(languages) > 0 {
+		if err := json.Unmarshal(languages, &job.Languages); err != nil {
+			return nil, err
+		}
+	}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &job.Options); err != nil {
+			return nil, err
+		}
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &job.Result); err != nil {
+			return nil, err
+		}
+	}
+	if len(timeline) > 0 {
+		if err := json.Unmarshal(timeline, &job.Timeline); err != nil {
+			return nil, err
+		}
+	}
+	if len(sentence) > 0 {
+		if err := json.Unmarshal(sentence, &job.Sentence); err != nil {
+			return nil, err
+		}
+	}
+	job.VideoKey = videoKey.String
+	job.ContentHash = contentHash.String
+	job.MLBackend = mlBackend.String
+	job.PredictionID = predictionID.Int64
+	job.Error = message.String
+	return &job, nil
+}
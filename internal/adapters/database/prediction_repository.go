@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type PredictionRepository struct {
+	db DBAdapter
+}
+
+func NewPredictionRepository(db DBAdapter) *PredictionRepository {
+	return &PredictionRepository{db: db}
+}
+
+// Create stores a prediction and fills in its ID.
+func (r *PredictionRepository) Create(p *entity.Prediction) error {
+	raw, err := json.Marshal(p.Raw)
+	if err != nil {
+		return err
+	}
+	avg, err := json.Marshal(p.Avg)
+	if err != nil {
+		return err
+	}
+	result, err := json.Marshal(p.Result)
+	if err != nil {
+		return err
+	}
+	timeline, err := json.Marshal(p.Timeline)
+	if err != nil {
+		return err
+	}
+	sentence, err := json.Marshal(p.Sentence)
+	if err != nil {
+		return err
+	}
+	timings, err := json.Marshal(p.Timings)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO predictions (sub, video_key, content_hash, model, status, cached, raw, avg, result, timeline, sentence, timings, error) VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''));"
+	res, err := r.db.Exec(query, p.Sub, p.VideoKey, p.ContentHash, p.Model, p.Status, p.Cached, raw, avg, result, timeline, sentence, timings, p.Error)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	p.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+// Get returns the prediction with the given ID owned by sub.
+func (r *PredictionRepository) Get(id int64, sub string) (*entity.Prediction, error) {
+	query := "SELECT id, sub, video_key, content_hash, model, status, cached, raw, avg, result, timeline, sentence, timings, error, created_at FROM predictions WHERE id = ? AND sub = ?;"
+	return scanPrediction(r.db.QueryRow(query, id, sub))
+}
+
+// List returns the predictions matching filter, newest first. Raw frames,
+// averages and timelines are left out to keep pages small. Classes match the
+// ranked result the user was shown, not the raw averages.
+func (r *PredictionRepository) List(filter entity.PredictionFilter) ([]entity.Prediction, error) {
+	query := "SELECT id, sub, video_key, content_hash, model, status, cached, NULL, NULL, result, NULL, sentence, timings, error, created_at FROM predictions WHERE sub = ?"
+	args := []interface{}{filter.Sub}
+
+	if filter.Before > 0 {
+		query += " AND id < ?"
+		args = append(args, filter.Before)
+	}
+	if filter.From != nil {
+		query += " AND created_at >= ?"
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += " AND created_at < ?"
+		args = append(args, filter.To.UTC())
+	}
+	if len(filter.Classes) > 0 {
+		conditions := make([]string, len(filter.Classes))
+		for i, class := range filter.Classes {
+			conditions[i] = "JSON_CONTAINS(result, JSON_OBJECT('class', ?))"
+			args = append(args, class)
+		}
+		query += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+	query += " ORDER BY id DESC LIMIT ?;"
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	predictions := []entity.Prediction{}
+	for rows.Next() {
+		p, err := scanPrediction(rows)
+		if err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, *p)
+	}
+	return predictions, rows.Err()
+}
+
+// CountByVideoKey returns how many predictions of sub refer to the same video.
+func (r *PredictionRepository) CountByVideoKey(sub string, videoKey string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM predictions WHERE sub = ? AND video_key = ?;", sub, videoKey).Scan(&count)
+	return count, err
+}
+
+// Videos returns the distinct video keys and content hashes of all predictions of sub.
+func (r *PredictionRepository) Videos(sub string) (keys []string, contentHashes []string, err error) {
+	rows, err := r.db.Query("SELECT DISTINCT video_key, content_hash FROM predictions WHERE sub = ?;", sub)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key         string
+			contentHash sql.NullString
+		)
+		if err := rows.Scan(&key, &contentHash); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		if contentHash.Valid {
+			contentHashes = append(contentHashes, contentHash.String)
+		}
+	}
+	return keys, contentHashes, rows.Err()
+}
+
+// Delete removes a single prediction of sub.
+func (r *PredictionRepository) Delete(id int64, sub string) error {
+	_, err := r.db.Exec("DELETE FROM predictions WHERE id = ? AND sub = ?;", id, sub)
+	return err
+}
+
+// DeleteBySub removes every prediction of sub and returns how many were removed.
+func (r *PredictionRepository) DeleteBySub(sub string) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM predictions WHERE sub = ?;", sub)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPrediction(row rowScanner) (*entity.Prediction, error) {
+	var (
+		p                                    entity.Prediction
+		contentHash, message                 sql.NullString
+		raw, avg, result, timeline, sentence []byte
+		timingsJSON                          []byte
+	)
+	err := row.Scan(&p.ID, &p.Sub, &p.VideoKey, &contentHash, &p.Model, &p.Status, &p.Cached, &raw, &avg, &result, &timeline, &sentence, &timingsJSON, &message, &p.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		data []byte
+		dest interface{}
+	}{
+		{raw, &p.Raw},
+		{avg, &p.Avg},
+		{result, &p.Result},
+		{timeline, &p.Timeline},
+		{sentence, &p.Sentence},
+		{timingsJSON, &p.Timings},
+	} {
+		if len(field.data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.data, field.dest); err != nil {
+			return nil, err
+		}
+	}
+	p.ContentHash = contentHash.String
+	p.Error = message.String
+	return &p, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// likeEscaper escapes the wildcards JSON_SEARCH would otherwise interpret.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// S3LinkRepository manages the per-user list of uploaded video links in S3_Table.
+type S3LinkRepository struct {
+	db DBAdapter
+}
+
+func NewS3LinkRepository(db DBAdapter) *S3LinkRepository {
+	return &S3LinkRepository{db: db}
+}
+
+// Links returns every link recorded for sub.
+func (r *S3LinkRepository) Links(sub string) ([]string, error) {
+	var data []byte
+	err := r.db.QueryRow("SELECT s3_links FROM S3_Table WHERE sub = ?;", sub).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) || len(data) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var links []string
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RemoveLink removes every occurrence of link from the list of sub.
+func (r *S3LinkRepository) RemoveLink(sub string, link string) error {
+	query := "UPDATE S3_Table SET s3_links = JSON_REMOVE(s3_links, JSON_UNQUOTE(JSON_SEARCH(s3_links, 'one', ?))) WHERE sub = ? AND JSON_SEARCH(s3_links, 'one', ?) IS NOT NULL;"
+	pattern := likeEscaper.Replace(link)
+	for {
+		result, err := r.db.Exec(query, pattern, sub, pattern)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return nil
+		}
+	}
+}
+
+// Delete removes the whole list of sub.
+func (r *S3LinkRepository) Delete(sub string) error {
+	_, err := r.db.Exec("DELETE FROM S3_Table WHERE sub = ?;", sub)
+	return err
+}
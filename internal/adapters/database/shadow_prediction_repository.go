@@ -0,0 +1,182 @@
+package database
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type ShadowPredictionRepository struct {
+	db DBAdapter
+}
+
+func NewShadowPredictionRepository(db DBAdapter) *ShadowPredictionRepository {
+	return &ShadowPredictionRepository{db: db}
+}
+
+// Create stores a comparison and the per-class confidences of both models,
+// a class missing from one of them counting as 0, and fills in its ID.
+func (r *ShadowPredictionRepository) Create(s *entity.ShadowPrediction) error {
+	primaryAvg, err := json.Marshal(s.PrimaryAvg)
+	if err != nil {
+		return err
+	}
+	shadowAvg, err := json.Marshal(s.ShadowAvg)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO shadow_predictions (prediction_id, primary_model, shadow_model, primary_class, shadow_class, agree, primary_avg, shadow_avg, inference_ms, error) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, NULLIF(?, ''));"
+	res, err := r.db.Exec(query, s.PredictionID, s.PrimaryModel, s.ShadowModel, s.PrimaryClass, s.ShadowClass, s.Agree, primaryAvg, shadowAvg, s.InferenceMs, s.Error)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	s.CreatedAt = time.Now().UTC()
+
+	if s.Error != "" {
+		return nil
+	}
+	classes := make(map[string]bool, len(s.PrimaryAvg)+len(s.ShadowAvg))
+	for class := range s.PrimaryAvg {
+		classes[class] = true
+	}
+	for class := range s.ShadowAvg {
+		classes[class] = true
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(classes))
+	args := make([]interface{}, 0, 4*len(classes))
+	for class := range classes {
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, id, class, s.PrimaryAvg[class].Average, s.ShadowAvg[class].Average)
+	}
+	_, err = r.db.Exec("INSERT INTO shadow_prediction_classes (shadow_prediction_id, class, primary_average, shadow_average) VALUES "+strings.Join(values, ", ")+";", args...)
+	return err
+}
+
+// Report summarises the comparisons matching filter. Classes are ordered by
+// how often the primary model predicted them, most often first.
+func (r *ShadowPredictionRepository) Report(filter entity.ShadowReportFilter) (*entity.ShadowReport, error) {
+	where := " WHERE s.shadow_model = ?"
+	args := []interface{}{filter.ShadowModel}
+	if filter.From != nil {
+		where += " AND s.created_at >= ?"
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		where += " AND s.created_at < ?"
+		args = append(args, *filter.To)
+	}
+
+	report := &entity.ShadowReport{ShadowModel: filter.ShadowModel, From: filter.From, To: filter.To, Classes: []entity.ShadowClassReport{}}
+	query := "SELECT COUNT(*), COALESCE(SUM(s.error IS NOT NULL), 0), COALESCE(SUM(s.agree), 0) FROM shadow_predictions s" + where + ";"
+	var total int64
+	if err := r.db.QueryRow(query, args...).Scan(&total, &report.Failed, &report.Agreed); err != nil {
+		return nil, err
+	}
+	report.Compared = total - report.Failed
+	report.AgreementRate = rate(report.Agreed, report.Compared)
+
+	classes := make(map[string]*entity.ShadowClassReport)
+	class := func(name string) *entity.ShadowClassReport {
+		if classes[name] == nil {
+			classes[name] = &entity.ShadowClassReport{Class: name}
+		}
+		return classes[name]
+	}
+
+	// Top-1 of the primary model and how often the shadow model agreed
+	query = "SELECT s.primary_class, COUNT(*), SUM(s.agree) FROM shadow_predictions s" + where + " AND s.error IS NULL AND s.primary_class IS NOT NULL GROUP BY s.primary_class;"
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var predicted, agreed int64
+		if err := rows.Scan(&name, &predicted, &agreed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := class(name)
+		c.Predicted, c.Agreed = predicted, agreed
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Top-1 of the shadow model
+	query = "SELECT s.shadow_class, COUNT(*) FROM shadow_predictions s" + where + " AND s.error IS NULL AND s.shadow_class IS NOT NULL GROUP BY s.shadow_class;"
+	rows, err = r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var predicted int64
+		if err := rows.Scan(&name, &predicted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		class(name).ShadowPredicted = predicted
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Confidence deltas over every comparison that saw the class
+	query = "SELECT c.class, COUNT(*), AVG(c.shadow_average - c.primary_average), AVG(ABS(c.shadow_average - c.primary_average)) FROM shadow_prediction_classes c JOIN shadow_predictions s ON s.id = c.shadow_prediction_id" + where + " GROUP BY c.class;"
+	rows, err = r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var seen int64
+		var delta, absDelta float64
+		if err := rows.Scan(&name, &seen, &delta, &absDelta); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := class(name)
+		c.Seen, c.ConfidenceDelta, c.AbsConfidenceDelta = seen, delta, absDelta
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, c := range classes {
+		c.AgreementRate = rate(c.Agreed, c.Predicted)
+		report.Classes = append(report.Classes, *c)
+	}
+	sort.Slice(report.Classes, func(i, j int) bool {
+		a, b := report.Classes[i], report.Classes[j]
+		if a.Predicted != b.Predicted {
+			return a.Predicted > b.Predicted
+		}
+		return a.Class < b.Class
+	})
+	return report, nil
+}
+
+// rate returns part/total, or 0 when total is 0.
+func rate(part int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
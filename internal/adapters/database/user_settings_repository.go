@@ -0,0 +1,60 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type UserSettingsRepository struct {
+	db DBAdapter
+}
+
+func NewUserSettingsRepository(db DBAdapter) *UserSettingsRepository {
+	return &UserSettingsRepository{db: db}
+}
+
+// Get returns the settings of sub, or ErrNotFound if the user never saved any.
+func (r *UserSettingsRepository) Get(sub string) (*entity.UserSettings, error) {
+	var (
+		settings        entity.UserSettings
+		defaultLanguage sql.NullString
+	)
+	err := r.db.QueryRow("SELECT default_language, training_consent FROM user_settings WHERE sub = ?;", sub).Scan(&defaultLanguage, &settings.TrainingConsent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings.DefaultLanguage = defaultLanguage.String
+	return &settings, nil
+}
+
+// Update changes the settings of sub that are set in update, creating them
+// with the defaults for the others if the user never saved any.
+func (r *UserSettingsRepository) Update(sub string, update *entity.UserSettingsUpdate) error {
+	var (
+		defaultLanguage string
+		trainingConsent bool
+		assignments     []string
+	)
+	if update.DefaultLanguage != nil {
+		defaultLanguage = *update.DefaultLanguage
+		assignments = append(assignments, "default_language = VALUES(default_language)")
+	}
+	if update.TrainingConsent != nil {
+		trainingConsent = *update.TrainingConsent
+		assignments = append(assignments, "training_consent = VALUES(training_consent)")
+	}
+	if len(assignments) == 0 {
+		assignments = append(assignments, "sub = sub")
+	}
+
+	query := "INSERT INTO user_settings (sub, default_language, training_consent) VALUES (?, NULLIF(?, ''), ?) ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ") + ";"
+	_, err := r.db.Exec(query, sub, defaultLanguage, trainingConsent)
+	return err
+}
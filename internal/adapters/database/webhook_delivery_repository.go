@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type WebhookDeliveryRepository struct {
+	db DBAdapter
+}
+
+func NewWebhookDeliveryRepository(db DBAdapter) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// Create stores a delivery that is due right away and fills in its ID.
+func (r *WebhookDeliveryRepository) Create(d *entity.WebhookDelivery) error {
+	now := time.Now().UTC()
+	if d.Status == "" {
+		d.Status = entity.WebhookDeliveryPending
+	}
+	if d.Status == entity.WebhookDeliveryPending && d.NextAttemptAt == nil {
+		d.NextAttemptAt = &now
+	}
+
+	query := "INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at) VALUES (?, ?, ?, ?, ?);"
+	res, err := r.db.Exec(query, d.WebhookID, d.Event, []byte(d.Payload), d.Status, d.NextAttemptAt)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	return nil
+}
+
+// ListDueIDs returns the IDs of pending deliveries whose next attempt is due,
+// oldest first.
+func (r *WebhookDeliveryRepository) ListDueIDs(limit int) ([]int64, error) {
+	rows, err := r.db.Query("SELECT id FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?;", entity.WebhookDeliveryPending, time.Now().UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Claim reserves a due delivery for lease by pushing its next attempt back,
+// so no other dispatcher sends it meanwhile and it is retried if this one
+// dies. It returns the delivery and its webhook, or false if the delivery is
+// not due or was claimed by someone else.
+func (r *WebhookDeliveryRepository) Claim(id int64, lease time.Duration) (*entity.WebhookDelivery, *entity.Webhook, bool, error) {
+	now := time.Now().UTC()
+	result, err := r.db.Exec("UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?;", now.Add(lease), id, entity.WebhookDeliveryPending, now)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if affected == 0 {
+		return nil, nil, false, nil
+	}
+
+	query := "SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.response_status, d.error, d.next_attempt_at, d.created_at, d.updated_at, w.url, w.secret FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id WHERE d.id = ?;"
+	var webhook entity.Webhook
+	delivery, err := scanDelivery(r.db.QueryRow(query, id), &webhook.URL, &webhook.Secret)
+	if errors.Is(err, ErrNotFound) {
+		// The webhook was deleted in the meantime
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	webhook.ID = delivery.WebhookID
+	return delivery, &webhook, true, nil
+}
+
+// Update stores the outcome of an attempt.
+func (r *WebhookDeliveryRepository) Update(d *entity.WebhookDelivery) error {
+	query := "UPDATE webhook_deliveries SET status = ?, attempts = ?, response_status = NULLIF(?, 0), error = NULLIF(?, ''), next_attempt_at = ? WHERE id = ?;"
+	_, err := r.db.Exec(query, d.Status, d.Attempts, d.ResponseStatus, d.Error, d.NextAttemptAt, d.ID)
+	return err
+}
+
+// ListByWebhook returns the latest deliveries of a webhook, newest first.
+func (r *WebhookDeliveryRepository) ListByWebhook(webhookID string, limit int) ([]entity.WebhookDelivery, error) {
+	query := "SELECT id, webhook_id, event, payload, status, attempts, response_status, error, next_attempt_at, created_at, updated_at FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?;"
+	rows, err := r.db.Query(query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []entity.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+// DeleteByPrediction removes the deliveries of sub's webhooks that carry the
+// prediction with id.
+func (r *WebhookDeliveryRepository) DeleteByPrediction(sub string, id int64) error {
+	query := "DELETE d FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id WHERE w.sub = ? AND d.event IN (?, ?) AND JSON_EXTRACT(d.payload, '$.data.id') = ?;"
+	_, err := r.db.Exec(query, sub, entity.WebhookPredictionSucceeded, entity.WebhookPredictionFailed, id)
+	return err
+}
+
+// DeleteBySub removes the deliveries of every prediction event sent to sub's
+// webhooks.
+func (r *WebhookDeliveryRepository) DeleteBySub(sub string) error {
+	query := "DELETE d FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id WHERE w.sub = ? AND d.event IN (?, ?);"
+	_, err := r.db.Exec(query, sub, entity.WebhookPredictionSucceeded, entity.WebhookPredictionFailed)
+	return err
+}
+
+// DeleteFinishedBefore removes every delivery created before cutoff that is
+// no longer pending.
+func (r *WebhookDeliveryRepository) DeleteFinishedBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM webhook_deliveries WHERE status <> ? AND created_at < ?;", entity.WebhookDeliveryPending, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanDelivery reads a delivery row followed by any extra columns.
+func scanDelivery(row rowScanner, extra ...interface{}) (*entity.WebhookDelivery, error) {
+	var (
+		d              entity.WebhookDelivery
+		payload        []byte
+		responseStatus sql.NullInt64
+		message        sql.NullString
+		nextAttemptAt  sql.NullTime
+	)
+	dest := append([]interface{}{&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &responseStatus, &message, &nextAttemptAt, &d.CreatedAt, &d.UpdatedAt}, extra...)
+	err := row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	d.ResponseStatus = int(responseStatus.Int64)
+	d.Error = message.String
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	return &d, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type WebhookRepository struct {
+	db DBAdapter
+}
+
+func NewWebhookRepository(db DBAdapter) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create stores a webhook and fills in its ID.
+func (r *WebhookRepository) Create(w *entity.Webhook) error {
+	id, err := NewID()
+	if err != nil {
+		return err
+	}
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO webhooks (id, sub, url, secret, events) VALUES (?, ?, ?, ?, ?);"
+	if _, err := r.db.Exec(query, id, w.Sub, w.URL, w.Secret, events); err != nil {
+		return err
+	}
+	w.ID = id
+	w.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+// List returns the webhooks of sub, oldest first, without their secrets.
+func (r *WebhookRepository) List(sub string) ([]entity.Webhook, error) {
+	rows, err := r.db.Query("SELECT id, sub, url, '', events, created_at FROM webhooks WHERE sub = ? ORDER BY created_at;", sub)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhooks(rows)
+}
+
+// ListForEvent returns the webhooks of sub subscribed to event, with their
+// secrets.
+func (r *WebhookRepository) ListForEvent(sub string, event string) ([]entity.Webhook, error) {
+	rows, err := r.db.Query("SELECT id, sub, url, secret, events, created_at FROM webhooks WHERE sub = ? AND JSON_CONTAINS(events, JSON_QUOTE(?));", sub, event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWebhooks(rows)
+}
+
+// Get returns the webhook with the given ID owned by sub, with its secret.
+func (r *WebhookRepository) Get(id string, sub string) (*entity.Webhook, error) {
+	return scanWebhook(r.db.QueryRow("SELECT id, sub, url, secret, events, created_at FROM webhooks WHERE id = ? AND sub = ?;", id, sub))
+}
+
+// Delete removes a webhook of sub together with its delivery log. It returns
+// ErrNotFound if there was none.
+func (r *WebhookRepository) Delete(id string, sub string) error {
+	result, err := r.db.Exec("DELETE FROM webhooks WHERE id = ? AND sub = ?;", id, sub)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanWebhooks(rows *sql.Rows) ([]entity.Webhook, error) {
+	webhooks := []entity.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+func scanWebhook(row rowScanner) (*entity.Webhook, error) {
+	var (
+		w      entity.Webhook
+		events []byte
+	)
+	err := row.Scan(&w.ID, &w.Sub, &w.URL, &w.Secret, &events, &w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &w.Events); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
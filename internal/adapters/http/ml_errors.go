@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of ML service failures. Use errors.Is to tell them apart.
+var (
+	// ErrMLUnavailable means the ML service could not be reached or failed
+	// on its side
+	ErrMLUnavailable = errors.New("ML service unavailable")
+	// ErrMLBadInput means the ML service rejected the video or window
+	ErrMLBadInput = errors.New("ML service rejected the input")
+	// ErrMLTimeout means the ML service did not answer in time
+	ErrMLTimeout = errors.New("ML service timed out")
+	// ErrMLMalformedResponse means the ML service answered with something
+	// that is not a valid result
+	ErrMLMalformedResponse = errors.New("ML service returned a malformed response")
+)
+
+// maxErrorSnippet is how much of an error response is kept for the logs.
+const maxErrorSnippet = 200
+
+// MLError is a failed call to the ML service. StatusCode is zero when no
+// response was received.
+type MLError struct {
+	Kind       error
+	StatusCode int
+	Err        error
+}
+
+func (e *MLError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *MLError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *MLError) Unwrap() error {
+	return e.Err
+}
+
+// mlRequestError classifies an error from sending a request or reading its
+// response. A cancelled context is returned as is, as nothing went wrong on
+// the ML side.
+func mlRequestError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &MLError{Kind: ErrMLTimeout, Err: err}
+	}
+	return &MLError{Kind: ErrMLUnavailable, Err: err}
+}
+
+// mlStatusError classifies an unsuccessful response status.
+func mlStatusError(status int, body []byte) error {
+	err := fmt.Errorf("status code %d: %s", status, snippet(body))
+	switch {
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return &MLError{Kind: ErrMLTimeout, StatusCode: status, Err: err}
+	case status == http.StatusTooManyRequests || status >= 500:
+		return &MLError{Kind: ErrMLUnavailable, StatusCode: status, Err: err}
+	case status >= 400:
+		return &MLError{Kind: ErrMLBadInput, StatusCode: status, Err: err}
+	default:
+		return &MLError{Kind: ErrMLMalformedResponse, StatusCode: status, Err: err}
+	}
+}
+
+// snippet returns the start of a response body for error messages.
+func snippet(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > maxErrorSnippet {
+		s = s[:maxErrorSnippet]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+		s += "..."
+	}
+	return s
+}
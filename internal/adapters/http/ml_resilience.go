@@ -0,0 +1,265 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrMLCircuitOpen means the call was refused without reaching the ML
+// service because it has been failing. It also matches ErrMLUnavailable.
+var ErrMLCircuitOpen = errors.New("ML circuit breaker is open")
+
+// CircuitOpenError is returned while the circuit breaker is open.
+// RetryAfter is how long until the breaker lets a call through again.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return ErrMLCircuitOpen.Error()
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrMLCircuitOpen || target == ErrMLUnavailable
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds, as sent
+// in a Retry-After header.
+func (e *CircuitOpenError) RetryAfterSeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// RetryPolicy controls how failed inference calls are retried. Only calls
+// that failed before the ML service could act on them, or with a status
+// that is safe to repeat, are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled for every
+	// following one up to MaxDelay. A random jitter of up to half the
+	// delay is taken off each wait.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// BreakerOptions control when the circuit breaker opens and closes.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed attempts that
+	// opens the breaker, 0 disables it
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before a single trial
+	// call is let through
+	OpenTimeout time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type resilientMLService struct {
+	next    MLService
+	policy  RetryPolicy
+	options BreakerOptions
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// probing is set while the trial call of a half-open breaker is running
+	probing bool
+}
+
+// NewResilientMLService wraps next with policy and a circuit breaker.
+// Health checks are passed through untouched.
+func NewResilientMLService(next MLService, policy RetryPolicy, options BreakerOptions, logger *zap.Logger) MLService {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &resilientMLService{
+		next:    next,
+		policy:  policy,
+		options: options,
+		logger:  logger,
+	}
+}
+
+func (s *resilientMLService) CheckHealth(ctx context.Context) error {
+	return s.next.CheckHealth(ctx)
+}
+
+// Predict calls the ML service until it succeeds, fails with an error that
+// is not worth retrying, or the attempts run out.
+func (s *resilientMLService) Predict(ctx context.Context, s3URI string) ([]byte, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err := s.allow(); err != nil {
+			return nil, err
+		}
+
+		var result []byte
+		result, err = s.next.Predict(ctx, s3URI)
+		s.record(err)
+		if err == nil {
+			return result, nil
+		}
+
+		if attempt >= s.policy.MaxAttempts || !retryable(err) {
+			return nil, err
+		}
+
+		delay := s.backoff(attempt)
+		s.logger.Warn("Retrying ML inference", zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the wait before the retry following attempt.
+func (s *resilientMLService) backoff(attempt int) time.Duration {
+	delay := s.policy.BaseDelay
+	for i := 1; i < attempt && delay < s.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if s.policy.MaxDelay > 0 && delay > s.policy.MaxDelay {
+		delay = s.policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// retryable reports whether a failed call can safely be repeated: the
+// connection failed before a response was received, or the ML service said
+// it is busy or was cut off by a gateway.
+func retryable(err error) bool {
+	var mlErr *MLError
+	if !errors.As(err, &mlErr) {
+		return false
+	}
+	switch mlErr.StatusCode {
+	case 0:
+		return mlErr.Kind == ErrMLUnavailable
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// countsAsFailure reports whether err means the ML service is down or
+// overloaded.
+func countsAsFailure(err error) bool {
+	return errors.Is(err, ErrMLUnavailable) || errors.Is(err, ErrMLTimeout)
+}
+
+// allow returns a *CircuitOpenError if the breaker does not let a call through.
+func (s *resilientMLService) allow() error {
+	if s.options.FailureThreshold <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case breakerOpen:
+		remaining := s.options.OpenTimeout - time.Since(s.openedAt)
+		if remaining > 0 {
+			return &CircuitOpenError{RetryAfter: remaining}
+		}
+		s.setState(breakerHalfOpen)
+		s.probing = true
+		return nil
+	case breakerHalfOpen:
+		if s.probing {
+			return &CircuitOpenError{RetryAfter: time.Second}
+		}
+		s.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a call it let through.
+func (s *resilientMLService) record(err error) {
+	if s.options.FailureThreshold <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	halfOpen := s.state == breakerHalfOpen
+	s.probing = false
+
+	switch {
+	case err == nil || errors.Is(err, ErrMLBadInput) || errors.Is(err, ErrMLMalformedResponse):
+		// The ML service answered, so it is up
+		s.failures = 0
+		if halfOpen {
+			s.setState(breakerClosed)
+		}
+	case countsAsFailure(err):
+		s.failures++
+		if halfOpen || (s.state == breakerClosed && s.failures >= s.options.FailureThreshold) {
+			s.openedAt = time.Now()
+			s.setState(breakerOpen)
+		}
+	}
+	// Anything else, e.g. a cancelled call, says nothing either way; a
+	// half-open breaker lets the next call through as a new trial
+}
+
+// setState changes the breaker state and logs the change. s.mu must be held.
+func (s *resilientMLService) setState(state breakerState) {
+	if s.state == state {
+		return
+	}
+	previous := s.state
+	s.state = state
+
+	fields := []zap.Field{zap.Stringer("from", previous), zap.Stringer("to", state)}
+	switch state {
+	case breakerOpen:
+		fields = append(fields, zap.Int("failures", s.failures), zap.Duration("open_for", s.options.OpenTimeout))
+		s.logger.Warn("ML circuit breaker opened", fields...)
+	case breakerHalfOpen:
+		s.logger.Info("ML circuit breaker half-open, letting a trial call through", fields...)
+	default:
+		s.logger.Info("ML circuit breaker closed", fields...)
+	}
+}
@@ -0,0 +1,336 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// scriptedML answers each Predict call with the next error of its script and
+// succeeds once the script runs out.
+type scriptedML struct {
+	mu     sync.Mutex
+	script []error
+	calls  int
+	// started and release, if set, hold up every call until release is
+	// closed, telling started when one begins
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *scriptedML) Predict(ctx context.Context, _ string) ([]byte, error) {
+	s.mu.Lock()
+	call := s.calls
+	s.calls++
+	s.mu.Unlock()
+
+	if s.release != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	if call < len(s.script) && s.script[call] != nil {
+		return nil, s.script[call]
+	}
+	return []byte(`{}`), nil
+}
+
+func (s *scriptedML) CheckHealth(context.Context) error {
+	return nil
+}
+
+func (s *scriptedML) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+var (
+	errConnRefused = &MLError{Kind: ErrMLUnavailable, Err: errors.New("connection refused")}
+	errNoAnswer    = &MLError{Kind: ErrMLTimeout, Err: context.DeadlineExceeded}
+	errBadVideo    = mlStatusError(http.StatusBadRequest, []byte("bad video"))
+	errCrashed     = mlStatusError(http.StatusInternalServerError, []byte("crashed"))
+	errBusy        = mlStatusError(http.StatusServiceUnavailable, []byte("busy"))
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection failed", errConnRefused, true},
+		{"no answer in time", errNoAnswer, false},
+		{"request timeout", mlStatusError(http.StatusRequestTimeout, nil), true},
+		{"too many requests", mlStatusError(http.StatusTooManyRequests, nil), true},
+		{"bad gateway", mlStatusError(http.StatusBadGateway, nil), true},
+		{"service unavailable", errBusy, true},
+		{"gateway timeout", mlStatusError(http.StatusGatewayTimeout, nil), true},
+		{"internal error", errCrashed, false},
+		{"bad input", errBadVideo, false},
+		{"malformed response", mlStatusError(http.StatusOK, []byte("hello")), false},
+		{"wrapped", fmt.Errorf("predict: %w", errBusy), true},
+		{"circuit open", &CircuitOpenError{RetryAfter: time.Second}, false},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResilientPredictRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      []error
+		maxAttempts int
+		wantCalls   int
+		wantErr     error
+	}{
+		{name: "succeeds first time", maxAttempts: 3, wantCalls: 1},
+		{name: "succeeds after retries", script: []error{errBusy, errConnRefused}, maxAttempts: 3, wantCalls: 3},
+		{name: "runs out of attempts", script: []error{errBusy, errBusy, errBusy}, maxAttempts: 3, wantCalls: 3, wantErr: errBusy},
+		{name: "retries disabled", script: []error{errBusy}, maxAttempts: 1, wantCalls: 1, wantErr: errBusy},
+		{name: "zero attempts means one", script: []error{errBusy}, maxAttempts: 0, wantCalls: 1, wantErr: errBusy},
+		{name: "bad input is not retried", script: []error{errBadVideo}, maxAttempts: 3, wantCalls: 1, wantErr: errBadVideo},
+		{name: "internal error is not retried", script: []error{errCrashed}, maxAttempts: 3, wantCalls: 1, wantErr: errCrashed},
+		{name: "timeout is not retried", script: []error{errNoAnswer}, maxAttempts: 3, wantCalls: 1, wantErr: errNoAnswer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedML{script: tt.script}
+			service := NewResilientMLService(next, RetryPolicy{MaxAttempts: tt.maxAttempts, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, BreakerOptions{}, zap.NewNop())
+
+			result, err := service.Predict(context.Background(), "s3://bucket/video.mp4")
+			if err != tt.wantErr {
+				t.Errorf("Predict error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(result) != `{}` {
+				t.Errorf("Predict = %s, want {}", result)
+			}
+			if next.Calls() != tt.wantCalls {
+				t.Errorf("ML service called %d times, want %d", next.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientPredictStopsRetryingWhenContextDone(t *testing.T) {
+	next := &scriptedML{script: []error{errBusy, errBusy}}
+	service := NewResilientMLService(next, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}, BreakerOptions{}, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := service.Predict(ctx, "s3://bucket/video.mp4")
+	if err != errBusy {
+		t.Errorf("Predict error = %v, want the last failure %v", err, errBusy)
+	}
+	if next.Calls() != 1 {
+		t.Errorf("ML service called %d times, want 1", next.Calls())
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Predict took %s after the context was done", elapsed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	service := &resilientMLService{policy: RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			// Jitter takes up to half the delay off
+			if got := service.backoff(tt.attempt); got < tt.max/2 || got > tt.max {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+
+	if got := (&resilientMLService{}).backoff(1); got != 0 {
+		t.Errorf("backoff without a base delay = %s, want 0", got)
+	}
+}
+
+func newTestBreaker(next MLService, threshold int, openTimeout time.Duration) *resilientMLService {
+	return NewResilientMLService(next, RetryPolicy{MaxAttempts: 1}, BreakerOptions{FailureThreshold: threshold, OpenTimeout: openTimeout}, zap.NewNop()).(*resilientMLService)
+}
+
+func (s *resilientMLService) currentState() breakerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func TestCircuitBreakerOpensAndCloses(t *testing.T) {
+	next := &scriptedML{script: []error{errConnRefused, errNoAnswer, errConnRefused}}
+	service := newTestBreaker(next, 2, 50*time.Millisecond)
+	ctx := context.Background()
+
+	// Two failures in a row open the breaker
+	for i := 0; i < 2; i++ {
+		if _, err := service.Predict(ctx, ""); err == nil {
+			t.Fatalf("call %d succeeded, want the scripted failure", i+1)
+		}
+	}
+	if state := service.currentState(); state != breakerOpen {
+		t.Fatalf("state after 2 failures = %s, want open", state)
+	}
+
+	// While open, calls are refused without reaching the ML service
+	_, err := service.Predict(ctx, "")
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("Predict error = %v, want a *CircuitOpenError", err)
+	}
+	if !errors.Is(err, ErrMLCircuitOpen) || !errors.Is(err, ErrMLUnavailable) {
+		t.Errorf("%v does not match ErrMLCircuitOpen and ErrMLUnavailable", err)
+	}
+	if openErr.RetryAfter <= 0 || openErr.RetryAfter > 50*time.Millisecond {
+		t.Errorf("RetryAfter = %s, want up to the open timeout", openErr.RetryAfter)
+	}
+	if next.Calls() != 2 {
+		t.Errorf("ML service called %d times while open, want 2", next.Calls())
+	}
+
+	// After the timeout a failed trial call opens it again
+	time.Sleep(60 * time.Millisecond)
+	if _, err := service.Predict(ctx, ""); err != errConnRefused {
+		t.Fatalf("trial call error = %v, want %v", err, errConnRefused)
+	}
+	if state := service.currentState(); state != breakerOpen {
+		t.Fatalf("state after a failed trial = %s, want open", state)
+	}
+	if _, err := service.Predict(ctx, ""); !errors.Is(err, ErrMLCircuitOpen) {
+		t.Errorf("Predict error = %v, want the breaker open again", err)
+	}
+
+	// A successful trial call closes it
+	time.Sleep(60 * time.Millisecond)
+	if _, err := service.Predict(ctx, ""); err != nil {
+		t.Fatalf("trial call error = %v, want nil", err)
+	}
+	if state := service.currentState(); state != breakerClosed {
+		t.Fatalf("state after a successful trial = %s, want closed", state)
+	}
+	if _, err := service.Predict(ctx, ""); err != nil {
+		t.Errorf("Predict error = %v once closed, want nil", err)
+	}
+	if next.Calls() != 5 {
+		t.Errorf("ML service called %d times, want 5", next.Calls())
+	}
+}
+
+func TestCircuitBreakerLetsOneTrialThrough(t *testing.T) {
+	next := &scriptedML{script: []error{errConnRefused}}
+	service := newTestBreaker(next, 1, 10*time.Millisecond)
+	service.Predict(context.Background(), "")
+	time.Sleep(20 * time.Millisecond)
+
+	next.started = make(chan struct{})
+	next.release = make(chan struct{})
+	trial := make(chan error)
+	go func() {
+		_, err := service.Predict(context.Background(), "")
+		trial <- err
+	}()
+	<-next.started
+
+	if state := service.currentState(); state != breakerHalfOpen {
+		t.Errorf("state during the trial = %s, want half-open", state)
+	}
+	if _, err := service.Predict(context.Background(), ""); !errors.Is(err, ErrMLCircuitOpen) {
+		t.Errorf("second call during the trial = %v, want refused", err)
+	}
+
+	close(next.release)
+	if err := <-trial; err != nil {
+		t.Errorf("trial call error = %v, want nil", err)
+	}
+	if state := service.currentState(); state != breakerClosed {
+		t.Errorf("state after the trial = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerCountsOnlyOutages(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []error
+		want   breakerState
+	}{
+		{"consecutive outages", []error{errConnRefused, errBusy, errNoAnswer}, breakerOpen},
+		{"bad input resets the count", []error{errConnRefused, errBusy, errBadVideo, errConnRefused, errBusy}, breakerClosed},
+		{"success resets the count", []error{errConnRefused, errBusy, nil, errConnRefused, errBusy}, breakerClosed},
+		{"cancelled calls do not count", []error{errConnRefused, context.Canceled, errBusy, context.Canceled}, breakerClosed},
+		{"internal errors count", []error{errCrashed, errCrashed, errCrashed}, breakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestBreaker(&scriptedML{script: tt.script}, 3, time.Minute)
+			for range tt.script {
+				service.Predict(context.Background(), "")
+			}
+			if state := service.currentState(); state != tt.want {
+				t.Errorf("state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	script := make([]error, 20)
+	for i := range script {
+		script[i] = errConnRefused
+	}
+	next := &scriptedML{script: script}
+	service := newTestBreaker(next, 0, time.Minute)
+	for range script {
+		if _, err := service.Predict(context.Background(), ""); err != errConnRefused {
+			t.Fatalf("Predict error = %v, want %v", err, errConnRefused)
+		}
+	}
+	if next.Calls() != len(script) {
+		t.Errorf("ML service called %d times, want %d", next.Calls(), len(script))
+	}
+}
+
+func TestCircuitOpenErrorRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1200 * time.Millisecond, 2},
+		{30 * time.Second, 30},
+	}
+
+	for _, tt := range tests {
+		err := &CircuitOpenError{RetryAfter: tt.retryAfter}
+		if got := err.RetryAfterSeconds(); got != tt.want {
+			t.Errorf("RetryAfterSeconds() for %s = %d, want %d", tt.retryAfter, got, tt.want)
+		}
+	}
+}
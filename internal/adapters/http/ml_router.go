@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// ErrUnknownMLBackend is returned when a backend is asked for by a name the
+// router does not know.
+var ErrUnknownMLBackend = errors.New("unknown ML backend")
+
+// MLBackend is a named model that serves a share of the predictions.
+type MLBackend struct {
+	// Name identifies the model. It is recorded with every prediction and
+	// keys the prediction cache.
+	Name string
+	// Weight is the backend's share of the traffic relative to the other
+	// backends. A backend with weight 0 only serves requests that ask for
+	// it by name.
+	Weight  int
+	Service MLService
+}
+
+// MLRouter spreads predictions over several backends by weight, e.g. to
+// roll out a new model to a small share of the traffic first.
+type MLRouter struct {
+	backends []MLBackend
+	total    int
+	// sticky routes all requests of a user to the same backend
+	sticky bool
+}
+
+var _ MLService = (*MLRouter)(nil)
+
+// MLRoute tells MLRouter.Predict whom a prediction is for and which backend
+// to use, and tells the caller which backend answered.
+type MLRoute struct {
+	// Sub is the user the prediction is for, used by sticky routing
+	Sub string
+	// Backend is the backend asked for by name, if any. Predict never fails
+	// over from it.
+	Backend string
+	// Model is the backend to try first, picked by Select when empty.
+	// Predict sets it to the backend that answered.
+	Model string
+}
+
+type mlRouteKey struct{}
+
+// WithMLRoute returns a copy of ctx that carries route to MLRouter.Predict.
+func WithMLRoute(ctx context.Context, route *MLRoute) context.Context {
+	return context.WithValue(ctx, mlRouteKey{}, route)
+}
+
+func NewMLRouter(backends []MLBackend, sticky bool) (*MLRouter, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("no ML backend configured")
+	}
+
+	router := &MLRouter{backends: backends, sticky: sticky}
+	seen := make(map[string]bool, len(backends))
+	for _, backend := range backends {
+		switch {
+		case backend.Name == "":
+			return nil, errors.New("ML backend without a name")
+		case seen[backend.Name]:
+			return nil, fmt.Errorf("ML backend %s is configured twice", backend.Name)
+		case backend.Weight < 0:
+			return nil, fmt.Errorf("ML backend %s has a negative weight", backend.Name)
+		}
+		seen[backend.Name] = true
+		router.total += backend.Weight
+	}
+	if router.total == 0 {
+		return nil, errors.New("every ML backend has weight 0")
+	}
+	return router, nil
+}
+
+// Has reports whether a backend is called name.
+func (r *MLRouter) Has(name string) bool {
+	_, ok := r.Backend(name)
+	return ok
+}
+
+// Backend returns the backend called name.
+func (r *MLRouter) Backend(name string) (*MLBackend, bool) {
+	for i := range r.backends {
+		if r.backends[i].Name == name {
+			return &r.backends[i], true
+		}
+	}
+	return nil, false
+}
+
+// Select returns the backend called name if it is set. Otherwise it picks
+// one by weight, at random or, with sticky routing, from sub so a user keeps
+// getting the same model.
+func (r *MLRouter) Select(sub string, name string) (*MLBackend, error) {
+	if name != "" {
+		backend, ok := r.Backend(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMLBackend, name)
+		}
+		return backend, nil
+	}
+
+	var point int
+	if r.sticky && sub != "" {
+		h := fnv.New32a()
+		h.Write([]byte(sub))
+		point = int(h.Sum32() % uint32(r.total))
+	} else {
+		point = rand.Intn(r.total)
+	}
+
+	for i := range r.backends {
+		if point < r.backends[i].Weight {
+			return &r.backends[i], nil
+		}
+		point -= r.backends[i].Weight
+	}
+	// Not reached, the weights add up to total
+	return &r.backends[len(r.backends)-1], nil
+}
+
+// Predict sends s3URI to the backend picked for the MLRoute of ctx, or to a
+// random one by weight without a route. When the circuit of a backend that
+// was not asked for by name is open, the other backends that take traffic
+// are tried in turn, so users pinned to it by sticky routing are still
+// served while it is down.
+func (r *MLRouter) Predict(ctx context.Context, s3URI string) ([]byte, error) {
+	route, _ := ctx.Value(mlRouteKey{}).(*MLRoute)
+	if route == nil {
+		route = &MLRoute{}
+	}
+
+	var first *MLBackend
+	if route.Model != "" {
+		backend, ok := r.Backend(route.Model)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMLBackend, route.Model)
+		}
+		first = backend
+	} else {
+		backend, err := r.Select(route.Sub, route.Backend)
+		if err != nil {
+			return nil, err
+		}
+		first = backend
+	}
+
+	result, err := first.Service.Predict(ctx, s3URI)
+	route.Model = first.Name
+	if err == nil || route.Backend != "" || !errors.Is(err, ErrMLCircuitOpen) {
+		return result, err
+	}
+
+	for i := range r.backends {
+		backend := &r.backends[i]
+		if backend == first || backend.Weight == 0 {
+			continue
+		}
+		result, fallbackErr := backend.Service.Predict(ctx, s3URI)
+		if errors.Is(fallbackErr, ErrMLCircuitOpen) {
+			continue
+		}
+		route.Model = backend.Name
+		return result, fallbackErr
+	}
+	// Every circuit is open
+	return nil, err
+}
+
+// CheckHealth checks every backend that takes traffic and reports the
+// router as healthy as long as one of them is, since Predict fails over to
+// it once the circuits of the others open.
+func (r *MLRouter) CheckHealth(ctx context.Context) error {
+	errs := make([]error, len(r.backends))
+	var wg sync.WaitGroup
+	for i, backend := range r.backends {
+		if backend.Weight == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, backend MLBackend) {
+			defer wg.Done()
+			if err := backend.Service.CheckHealth(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", backend.Name, err)
+			}
+		}(i, backend)
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, backend := range r.backends {
+		if backend.Weight == 0 {
+			continue
+		}
+		if errs[i] == nil {
+			return nil
+		}
+		failed = append(failed, errs[i])
+	}
+	return errors.Join(failed...)
+}
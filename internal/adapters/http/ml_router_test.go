@@ -0,0 +1,293 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// healthML is an MLService whose health check returns err.
+type healthML struct {
+	err error
+}
+
+func (s healthML) Predict(context.Context, string) ([]byte, error) {
+	return []byte(`{}`), nil
+}
+
+func (s healthML) CheckHealth(context.Context) error {
+	return s.err
+}
+
+func newTestRouter(t *testing.T, sticky bool, weights ...int) *MLRouter {
+	t.Helper()
+	backends := make([]MLBackend, len(weights))
+	for i, weight := range weights {
+		backends[i] = MLBackend{Name: fmt.Sprintf("v%d", i+1), Weight: weight, Service: healthML{}}
+	}
+	router, err := NewMLRouter(backends, sticky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestNewMLRouterRejectsInvalidBackends(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends []MLBackend
+	}{
+		{"none", nil},
+		{"unnamed", []MLBackend{{Weight: 1}}},
+		{"duplicate name", []MLBackend{{Name: "v1", Weight: 1}, {Name: "v1", Weight: 1}}},
+		{"negative weight", []MLBackend{{Name: "v1", Weight: 1}, {Name: "v2", Weight: -1}}},
+		{"no weight at all", []MLBackend{{Name: "v1"}, {Name: "v2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMLRouter(tt.backends, false); err == nil {
+				t.Error("NewMLRouter succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestMLRouterSelectByName(t *testing.T) {
+	router := newTestRouter(t, true, 1, 0)
+
+	for _, name := range []string{"v1", "v2"} {
+		backend, err := router.Select("user", name)
+		if err != nil || backend.Name != name {
+			t.Errorf("Select(%q) = %v, %v, want %s", name, backend, err, name)
+		}
+	}
+	if _, err := router.Select("user", "v3"); !errors.Is(err, ErrUnknownMLBackend) {
+		t.Errorf("Select(v3) error = %v, want %v", err, ErrUnknownMLBackend)
+	}
+	if !router.Has("v2") || router.Has("v3") {
+		t.Error("Has does not match the configured backends")
+	}
+}
+
+func TestMLRouterSelectByWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		sticky  bool
+		weights []int
+	}{
+		{"random 90/10", false, []int{90, 10}},
+		{"random 1/2/1", false, []int{1, 2, 1}},
+		{"random with a named-only backend", false, []int{3, 0, 1}},
+		{"sticky 90/10", true, []int{90, 10}},
+		{"sticky 1/2/1", true, []int{1, 2, 1}},
+	}
+
+	const picks = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, tt.sticky, tt.weights...)
+			total := 0
+			for _, weight := range tt.weights {
+				total += weight
+			}
+
+			counts := make(map[string]int)
+			for i := 0; i < picks; i++ {
+				// Sticky routing spreads by user, so every pick is a new one
+				backend, err := router.Select(fmt.Sprintf("user-%d", i), "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				counts[backend.Name]++
+			}
+
+			for i, weight := range tt.weights {
+				name := fmt.Sprintf("v%d", i+1)
+				want := float64(weight) / float64(total)
+				got := float64(counts[name]) / picks
+				if weight == 0 && counts[name] > 0 {
+					t.Errorf("%s has weight 0 but got %d picks", name, counts[name])
+				}
+				if math.Abs(got-want) > 0.02 {
+					t.Errorf("%s got %.3f of the picks, want %.3f", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMLRouterStickySelection(t *testing.T) {
+	router := newTestRouter(t, true, 1, 1, 1)
+
+	for i := 0; i < 100; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		first, _ := router.Select(user, "")
+		for j := 0; j < 10; j++ {
+			if backend, _ := router.Select(user, ""); backend.Name != first.Name {
+				t.Fatalf("%s got %s after %s", user, backend.Name, first.Name)
+			}
+		}
+	}
+
+	// Requests without a user cannot stick, so they are spread at random
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		backend, _ := router.Select("", "")
+		seen[backend.Name] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("anonymous requests reached %d backends, want 3", len(seen))
+	}
+}
+
+func TestMLRouterCheckHealth(t *testing.T) {
+	down := errors.New("down")
+	tests := []struct {
+		name     string
+		backends []MLBackend
+		healthy  bool
+	}{
+		{"all up", []MLBackend{{Name: "v1", Weight: 1, Service: healthML{}}, {Name: "v2", Weight: 1, Service: healthML{}}}, true},
+		{"one up", []MLBackend{{Name: "v1", Weight: 1, Service: healthML{down}}, {Name: "v2", Weight: 1, Service: healthML{}}}, true},
+		{"all down", []MLBackend{{Name: "v1", Weight: 1, Service: healthML{down}}, {Name: "v2", Weight: 1, Service: healthML{down}}}, false},
+		{"only the unweighted one up", []MLBackend{{Name: "v1", Weight: 1, Service: healthML{down}}, {Name: "v2", Service: healthML{}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := NewMLRouter(tt.backends, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = router.CheckHealth(context.Background())
+			if tt.healthy && err != nil {
+				t.Errorf("CheckHealth = %v, want nil", err)
+			}
+			if !tt.healthy && !errors.Is(err, down) {
+				t.Errorf("CheckHealth = %v, want %v", err, down)
+			}
+		})
+	}
+}
+
+// namedML answers Predict with its name, or with err if it is set.
+type namedML struct {
+	name string
+	err  error
+}
+
+func (s namedML) Predict(context.Context, string) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []byte(s.name), nil
+}
+
+func (s namedML) CheckHealth(context.Context) error {
+	return nil
+}
+
+func TestMLRouterPredict(t *testing.T) {
+	open := &CircuitOpenError{RetryAfter: time.Second}
+	down := mlStatusError(http.StatusInternalServerError, nil)
+	tests := []struct {
+		name      string
+		backends  []MLBackend
+		route     *MLRoute
+		wantModel string
+		wantErr   error
+	}{
+		{
+			name:      "routed by weight",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{name: "v1"}}, {Name: "v2", Service: namedML{name: "v2"}}},
+			route:     &MLRoute{Sub: "user"},
+			wantModel: "v1",
+		},
+		{
+			name:      "asked for by name",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{name: "v1"}}, {Name: "v2", Service: namedML{name: "v2"}}},
+			route:     &MLRoute{Sub: "user", Backend: "v2"},
+			wantModel: "v2",
+		},
+		{
+			name:      "model picked by the caller",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{name: "v1"}}, {Name: "v2", Weight: 1, Service: namedML{name: "v2"}}},
+			route:     &MLRoute{Model: "v2"},
+			wantModel: "v2",
+		},
+		{
+			name:      "fails over from an open circuit",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{err: open}}, {Name: "v2", Service: namedML{name: "v2"}}, {Name: "v3", Weight: 1, Service: namedML{name: "v3"}}},
+			route:     &MLRoute{Model: "v1"},
+			wantModel: "v3",
+		},
+		{
+			name:      "skips other open circuits",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{err: open}}, {Name: "v2", Weight: 1, Service: namedML{err: open}}, {Name: "v3", Weight: 1, Service: namedML{name: "v3"}}},
+			route:     &MLRoute{Model: "v1"},
+			wantModel: "v3",
+		},
+		{
+			name:      "reports the failing fallback",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{err: open}}, {Name: "v2", Weight: 1, Service: namedML{err: down}}},
+			route:     &MLRoute{Model: "v1"},
+			wantModel: "v2",
+			wantErr:   down,
+		},
+		{
+			name:      "every circuit open",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{err: open}}, {Name: "v2", Weight: 1, Service: namedML{err: open}}},
+			route:     &MLRoute{Model: "v1"},
+			wantModel: "v1",
+			wantErr:   open,
+		},
+		{
+			name:      "no failover from a backend asked for by name",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{name: "v1"}}, {Name: "v2", Service: namedML{err: open}}},
+			route:     &MLRoute{Backend: "v2", Model: "v2"},
+			wantModel: "v2",
+			wantErr:   open,
+		},
+		{
+			name:      "no failover from other failures",
+			backends:  []MLBackend{{Name: "v1", Weight: 1, Service: namedML{err: down}}, {Name: "v2", Weight: 1, Service: namedML{name: "v2"}}},
+			route:     &MLRoute{Model: "v1"},
+			wantModel: "v1",
+			wantErr:   down,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := NewMLRouter(tt.backends, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := router.Predict(WithMLRoute(context.Background(), tt.route), "s3://bucket/video.mp4")
+			if err != tt.wantErr {
+				t.Fatalf("Predict error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(result) != tt.wantModel {
+				t.Errorf("Predict answered by %s, want %s", result, tt.wantModel)
+			}
+			if tt.route.Model != tt.wantModel {
+				t.Errorf("route model = %s, want %s", tt.route.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestMLRouterPredictWithoutRoute(t *testing.T) {
+	router := newTestRouter(t, false, 1, 0)
+	if _, err := router.Predict(context.Background(), "s3://bucket/video.mp4"); err != nil {
+		t.Errorf("Predict = %v, want nil", err)
+	}
+	if _, err := router.Predict(WithMLRoute(context.Background(), &MLRoute{Model: "v3"}), ""); !errors.Is(err, ErrUnknownMLBackend) {
+		t.Errorf("Predict error = %v, want %v", err, ErrUnknownMLBackend)
+	}
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sagemakerruntime"
+)
+
+// SageMakerOptions locate a SageMaker real-time endpoint.
+type SageMakerOptions struct {
+	// Endpoint is the name of the SageMaker endpoint
+	Endpoint string
+	Region   string
+	// EndpointURL replaces the AWS service URL, e.g. to call a local
+	// stand-in during development
+	EndpointURL string
+}
+
+// sageMakerRequest is the payload sent to the model, the same input the
+// HTTP backend receives as a query parameter.
+type sageMakerRequest struct {
+	S3URI string `json:"s3_uri"`
+}
+
+type sageMakerMLService struct {
+	endpoint string
+	runtime  *sagemakerruntime.SageMakerRuntime
+	options  MLClientOptions
+}
+
+// NewSageMakerMLService returns an MLService that invokes a SageMaker
+// real-time endpoint. The model is expected to answer like the HTTP
+// backend's /predict. Like NewMLService it does not contact the endpoint.
+func NewSageMakerMLService(sageMaker SageMakerOptions, creds *credentials.Credentials, options MLClientOptions) (MLService, error) {
+	if sageMaker.Endpoint == "" {
+		return nil, errors.New("SageMaker endpoint name is not set")
+	}
+
+	config := &aws.Config{
+		Region:      aws.String(sageMaker.Region),
+		Credentials: creds,
+		HTTPClient:  newMLClient(options),
+		// Failed calls are retried by the caller, which knows which
+		// failures are worth it
+		MaxRetries: aws.Int(0),
+	}
+	if sageMaker.EndpointURL != "" {
+		config.Endpoint = aws.String(sageMaker.EndpointURL)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sageMakerMLService{
+		endpoint: sageMaker.Endpoint,
+		runtime:  sagemakerruntime.New(sess),
+		options:  options,
+	}, nil
+}
+
+// CheckHealth invokes the endpoint with an empty request. Any answer from
+// the model, including a rejection of the request, means the endpoint is in
+// service, so only InvokeEndpoint permission is needed.
+func (s *sageMakerMLService) CheckHealth(ctx context.Context) error {
+	if s.options.HealthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.options.HealthTimeout)
+		defer cancel()
+	}
+
+	_, err := s.invoke(ctx, sageMakerRequest{})
+	if err != nil && !errors.Is(err, ErrMLBadInput) {
+		return err
+	}
+	return nil
+}
+
+// Predict invokes the endpoint with the S3 URI of the video and returns the
+// JSON response. Failures are reported as an *MLError.
+func (s *sageMakerMLService) Predict(ctx context.Context, s3URI string) ([]byte, error) {
+	body, err := s.invoke(ctx, sageMakerRequest{S3URI: s3URI})
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(body) {
+		return nil, &MLError{Kind: ErrMLMalformedResponse, StatusCode: http.StatusOK, Err: fmt.Errorf("response is not JSON: %s", snippet(body))}
+	}
+	return body, nil
+}
+
+func (s *sageMakerMLService) invoke(ctx context.Context, request sageMakerRequest) ([]byte, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.runtime.InvokeEndpointWithContext(ctx, &sagemakerruntime.InvokeEndpointInput{
+		EndpointName: aws.String(s.endpoint),
+		ContentType:  aws.String("application/json"),
+		Accept:       aws.String("application/json"),
+		Body:         payload,
+	})
+	if err != nil {
+		return nil, sageMakerError(ctx, err)
+	}
+	return output.Body, nil
+}
+
+// sageMakerError classifies an error returned by the SageMaker client.
+func sageMakerError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return mlRequestError(ctxErr)
+	}
+
+	// The model container itself failed; judge it by the status it answered
+	// with
+	var modelErr *sagemakerruntime.ModelError
+	if errors.As(err, &modelErr) {
+		status := int(aws.Int64Value(modelErr.OriginalStatusCode))
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		return mlStatusError(status, []byte(aws.StringValue(modelErr.OriginalMessage)))
+	}
+
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		// The payload is always well-formed, so a validation error means
+		// the endpoint is missing or misconfigured
+		if reqErr.Code() == "ValidationError" || reqErr.Code() == "ValidationException" {
+			return &MLError{Kind: ErrMLUnavailable, StatusCode: reqErr.StatusCode(), Err: err}
+		}
+		return mlStatusError(reqErr.StatusCode(), []byte(reqErr.Message()))
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.OrigErr() != nil {
+		return mlRequestError(awsErr.OrigErr())
+	}
+	return mlRequestError(err)
+}
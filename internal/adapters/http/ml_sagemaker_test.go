@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// sageMakerStub stands in for the SageMaker runtime API, like cmd/mlstub.
+// handle answers the invocations of endpoint with the decoded payload.
+func sageMakerStub(t *testing.T, endpoint string, handle func(w http.ResponseWriter, payload sageMakerRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/endpoints/"+endpoint+"/invocations" {
+			writeSageMakerError(w, http.StatusBadRequest, "ValidationError", map[string]string{"message": "Endpoint " + strings.TrimPrefix(r.URL.Path, "/endpoints/") + " not found"})
+			return
+		}
+		var payload sageMakerRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("payload: %v", err)
+		}
+		handle(w, payload)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeSageMakerError(w http.ResponseWriter, status int, code string, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-ErrorType", code)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeModelError answers like SageMaker when the model container responded
+// with status.
+func writeModelError(w http.ResponseWriter, status int, message string) {
+	writeSageMakerError(w, http.StatusFailedDependency, "ModelError", map[string]interface{}{
+		"message":            "Received client error from model",
+		"OriginalStatusCode": status,
+		"OriginalMessage":    message,
+	})
+}
+
+func newTestSageMaker(t *testing.T, server *httptest.Server, endpoint string, options MLClientOptions) MLService {
+	t.Helper()
+	service, err := NewSageMakerMLService(SageMakerOptions{Endpoint: endpoint, Region: "us-east-1", EndpointURL: server.URL}, credentials.NewStaticCredentials("id", "secret", ""), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestSageMakerPredict(t *testing.T) {
+	result := `{"results":{"raw":[],"avg":{"hello":{"average":0.9,"sum":0.9,"count":1}}}}`
+	server := sageMakerStub(t, "sign-model", func(w http.ResponseWriter, payload sageMakerRequest) {
+		if payload.S3URI != "s3://bucket/video.mp4" {
+			writeModelError(w, http.StatusBadRequest, "unexpected s3_uri "+payload.S3URI)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(result))
+	})
+	service := newTestSageMaker(t, server, "sign-model", MLClientOptions{})
+
+	body, err := service.Predict(context.Background(), "s3://bucket/video.mp4")
+	if err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if string(body) != result {
+		t.Errorf("Predict = %s, want %s", body, result)
+	}
+}
+
+func TestSageMakerPredictErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		handle   func(w http.ResponseWriter, payload sageMakerRequest)
+		kind     error
+		status   int
+	}{
+		{
+			name: "model rejects the input",
+			handle: func(w http.ResponseWriter, _ sageMakerRequest) {
+				writeModelError(w, http.StatusBadRequest, "cannot decode video")
+			},
+			kind:   ErrMLBadInput,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "model fails",
+			handle: func(w http.ResponseWriter, _ sageMakerRequest) {
+				writeModelError(w, http.StatusInternalServerError, "out of memory")
+			},
+			kind:   ErrMLUnavailable,
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:     "endpoint missing",
+			endpoint: "other-model",
+			handle:   func(http.ResponseWriter, sageMakerRequest) {},
+			kind:     ErrMLUnavailable,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name: "throttled",
+			handle: func(w http.ResponseWriter, _ sageMakerRequest) {
+				writeSageMakerError(w, http.StatusTooManyRequests, "ThrottlingException", map[string]string{"message": "slow down"})
+			},
+			kind:   ErrMLUnavailable,
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name: "response is not JSON",
+			handle: func(w http.ResponseWriter, _ sageMakerRequest) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("hello"))
+			},
+			kind:   ErrMLMalformedResponse,
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sageMakerStub(t, "sign-model", tt.handle)
+			endpoint := tt.endpoint
+			if endpoint == "" {
+				endpoint = "sign-model"
+			}
+			service := newTestSageMaker(t, server, endpoint, MLClientOptions{})
+
+			_, err := service.Predict(context.Background(), "s3://bucket/video.mp4")
+			var mlErr *MLError
+			if !errors.As(err, &mlErr) {
+				t.Fatalf("Predict error = %v, want an *MLError", err)
+			}
+			if !errors.Is(err, tt.kind) || mlErr.StatusCode != tt.status {
+				t.Errorf("Predict error = %v with status %d, want %v with status %d", err, mlErr.StatusCode, tt.kind, tt.status)
+			}
+		})
+	}
+}
+
+func TestSageMakerPredictTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	server := sageMakerStub(t, "sign-model", func(w http.ResponseWriter, _ sageMakerRequest) {
+		<-done
+	})
+
+	t.Run("client timeout", func(t *testing.T) {
+		service := newTestSageMaker(t, server, "sign-model", MLClientOptions{RequestTimeout: 100 * time.Millisecond})
+		_, err := service.Predict(context.Background(), "s3://bucket/video.mp4")
+		if !errors.Is(err, ErrMLTimeout) {
+			t.Errorf("Predict error = %v, want %v", err, ErrMLTimeout)
+		}
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		service := newTestSageMaker(t, server, "sign-model", MLClientOptions{})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := service.Predict(ctx, "s3://bucket/video.mp4")
+		if !errors.Is(err, ErrMLTimeout) {
+			t.Errorf("Predict error = %v, want %v", err, ErrMLTimeout)
+		}
+	})
+}
+
+func TestSageMakerCheckHealth(t *testing.T) {
+	t.Run("model answers", func(t *testing.T) {
+		server := sageMakerStub(t, "sign-model", func(w http.ResponseWriter, payload sageMakerRequest) {
+			if payload.S3URI == "" {
+				writeModelError(w, http.StatusBadRequest, "s3_uri is required")
+			}
+		})
+		service := newTestSageMaker(t, server, "sign-model", MLClientOptions{})
+		if err := service.CheckHealth(context.Background()); err != nil {
+			t.Errorf("CheckHealth = %v, want nil", err)
+		}
+	})
+
+	t.Run("endpoint missing", func(t *testing.T) {
+		server := sageMakerStub(t, "sign-model", nil)
+		service := newTestSageMaker(t, server, "other-model", MLClientOptions{})
+		if err := service.CheckHealth(context.Background()); !errors.Is(err, ErrMLUnavailable) {
+			t.Errorf("CheckHealth = %v, want %v", err, ErrMLUnavailable)
+		}
+	})
+}
@@ -1,46 +1,74 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"time"
 )
 
+// maxPredictResponseBytes bounds the ML response read for a whole video.
+const maxPredictResponseBytes = 32 << 20
+
 type MLService interface {
-	Predict(inputData string) ([]byte, error)
-	CheckHealth() error
+	Predict(ctx context.Context, s3URI string) ([]byte, error)
+	CheckHealth(ctx context.Context) error
+}
+
+// MLClientOptions tune the HTTP client used to call the ML service.
+type MLClientOptions struct {
+	// RequestTimeout bounds a whole inference call, including reading the response
+	RequestTimeout time.Duration
+	ConnectTimeout time.Duration
+	HealthTimeout  time.Duration
+	// MaxIdleConns is how many connections to the ML service are kept open
+	// for reuse
+	MaxIdleConns int
 }
 
 type mlServiceImpl struct {
 	baseURL string
+	client  *http.Client
+	options MLClientOptions
 }
 
-func NewMLService(baseURL string) (MLService, error) {
-	mlService := &mlServiceImpl{
-		baseURL: baseURL,
+// NewMLService returns a client for the ML service at baseURL. It does not
+// contact the service, so the API can start while the ML service is down.
+func NewMLService(baseURL string, options MLClientOptions) (MLService, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
 	}
-
-	attempts := 3
-	delay := 5 * time.Second
-
-	// Perform a health check
-	for i := 0; i < attempts; i++ {
-		err := mlService.CheckHealth()
-		if err == nil {
-			return mlService, nil
-		}
-		log.Printf("Health check failed: %v", err)
-		time.Sleep(delay)
-		delay *= 2
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("ML service endpoint %q is not an absolute URL", baseURL)
 	}
 
-	return nil, fmt.Errorf("ML service is not healthy after %d attempts", attempts)
+	return &mlServiceImpl{
+		baseURL: baseURL,
+		client:  newMLClient(options),
+		options: options,
+	}, nil
+}
+
+// newMLClient returns a client that keeps connections to the ML service
+// alive between calls and gives up on calls that take too long.
+func newMLClient(options MLClientOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: options.ConnectTimeout, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.MaxIdleConns = options.MaxIdleConns
+	transport.MaxIdleConnsPerHost = options.MaxIdleConns
+	return &http.Client{
+		Timeout:   options.RequestTimeout,
+		Transport: transport,
+	}
 }
 
-func (s *mlServiceImpl) CheckHealth() error {
+func (s *mlServiceImpl) CheckHealth(ctx context.Context) error {
 	// Parse the endpoint URL
 	u, err := url.Parse(s.baseURL)
 	if err != nil {
@@ -50,31 +78,36 @@ func (s *mlServiceImpl) CheckHealth() error {
 	// Add /healthz to the path
 	u.Path += "/healthz"
 
-	// Construct the full URL
-	url := u.String()
+	if s.options.HealthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.options.HealthTimeout)
+		defer cancel()
+	}
 
 	// Perform the HTTP GET request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
-		return err
+		return mlRequestError(err)
 	}
 	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
 
 	// Check the HTTP status code
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("ML service health check failed with status code: %d", resp.StatusCode)
+		return &MLError{Kind: ErrMLUnavailable, StatusCode: resp.StatusCode, Err: fmt.Errorf("health check failed with status code: %d", resp.StatusCode)}
 	}
 
 	return nil
 }
 
-func (s *mlServiceImpl) Predict(s3URI string) ([]byte, error) {
+// Predict runs inference on the video at s3URI and returns the JSON response.
+// Failures are reported as an *MLError.
+func (s *mlServiceImpl) Predict(ctx context.Context, s3URI string) ([]byte, error) {
 	// Parse the endpoint URL
 	u, err := url.Parse(s.baseURL)
 	if err != nil {
@@ -89,31 +122,39 @@ func (s *mlServiceImpl) Predict(s3URI string) ([]byte, error) {
 	queryParams.Add("s3_uri", s3URI)
 	u.RawQuery = queryParams.Encode()
 
-	// Construct the full URL with the encoded query parameters
-	url := u.String()
-
 	// Perform the HTTP GET request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, mlRequestError(err)
 	}
 	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
+	return readMLResponse(resp, maxPredictResponseBytes)
+}
+
+// readMLResponse returns the body of a successful ML response, making sure
+// it is JSON, or the error matching the response status.
+func readMLResponse(resp *http.Response, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		return nil, mlRequestError(err)
 	}
 
-	// Return the response body as a byte slice
+	if resp.StatusCode != http.StatusOK {
+		return nil, mlStatusError(resp.StatusCode, body)
+	}
+	if int64(len(body)) > limit {
+		return nil, &MLError{Kind: ErrMLMalformedResponse, StatusCode: resp.StatusCode, Err: fmt.Errorf("response is larger than %d bytes", limit)}
+	}
+	if !json.Valid(body) {
+		return nil, &MLError{Kind: ErrMLMalformedResponse, StatusCode: resp.StatusCode, Err: fmt.Errorf("response is not JSON: %s", snippet(body))}
+	}
 	return body, nil
 }
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+)
+
+// maxStreamResponseBytes bounds the ML response read for a single window.
+const maxStreamResponseBytes = 1 << 20
+
+// StreamWindow is a slice of a live stream sent to the ML service in one
+// request. Parts holds either consecutive encoded frames or consecutive
+// chunks of a video container, all of ContentType.
+type StreamWindow struct {
+	Session     string
+	Seq         int
+	ContentType string
+	Parts       [][]byte
+}
+
+// MLStreamService runs inference on windows of a live stream. The ML
+// service keeps per-session state, e.g. the video decoder, between windows
+// of the same session.
+type MLStreamService interface {
+	PredictWindow(ctx context.Context, window StreamWindow) ([]byte, error)
+}
+
+type mlStreamServiceImpl struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewMLStreamService(baseURL string, options MLClientOptions) MLStreamService {
+	return &mlStreamServiceImpl{
+		baseURL: baseURL,
+		client:  newMLClient(options),
+	}
+}
+
+// PredictWindow posts a window as multipart/form-data to /stream/predict and
+// returns the response body, which has the same shape as a /predict result.
+// Failures are reported as an *MLError.
+func (s *mlStreamServiceImpl) PredictWindow(ctx context.Context, window StreamWindow) ([]byte, error) {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/stream/predict"
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("session", window.Session); err != nil {
+		return nil, err
+	}
+	if err := form.WriteField("seq", strconv.Itoa(window.Seq)); err != nil {
+		return nil, err
+	}
+	for i, data := range window.Parts {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="data"; filename="%d"`, i))
+		header.Set("Content-Type", window.ContentType)
+		part, err := form.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, mlRequestError(err)
+	}
+	defer resp.Body.Close()
+
+	return readMLResponse(resp, maxStreamResponseBytes)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every webhook call.
+const (
+	WebhookEventHeader     = "X-Manu-Event"
+	WebhookDeliveryHeader  = "X-Manu-Delivery"
+	WebhookTimestampHeader = "X-Manu-Timestamp"
+	WebhookSignatureHeader = "X-Manu-Signature"
+)
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address on
+// a private network and private addresses are not allowed.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// WebhookSender posts signed event payloads to webhook URLs.
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender returns a sender whose calls time out after timeout. Unless
+// allowPrivate is set, connections to loopback, private and link-local
+// addresses are refused, so webhooks cannot be used to reach internal services.
+func NewWebhookSender(timeout time.Duration, allowPrivate bool) *WebhookSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Through a proxy the dialer would only ever see the proxy's address,
+	// so webhooks are always called directly
+	transport.Proxy = nil
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// A redirect could point anywhere, so it is reported as is
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// SignWebhook returns the signature of a payload sent at timestamp: the hex
+// HMAC-SHA256, keyed with the webhook secret, of the timestamp in Unix
+// seconds, a dot and the payload.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts payload to url and returns the response status. Any status
+// outside 2xx is returned together with an error.
+func (s *WebhookSender) Send(ctx context.Context, url string, secret string, event string, deliveryID string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Manu-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -2,14 +2,40 @@ package s3
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
+const (
+	// UploadPartSize is the size of each part sent by Upload. At most
+	// UploadConcurrency+1 parts are buffered at a time.
+	UploadPartSize = 5 * 1024 * 1024
+	// UploadConcurrency is the number of parts Upload sends in parallel.
+	UploadConcurrency = 3
+)
+
+// ErrObjectNotFound is returned when the requested key does not exist in the bucket.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object without its content.
+type ObjectInfo struct {
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
 type S3Adapter struct {
 	Session *session.Session
 	Bucket  string
@@ -50,6 +76,68 @@ func (s *S3Adapter) GetObject(key string) ([]byte, error) {
 	return data, nil
 }
 
+// OpenObject returns a stream of the object's content. The caller must close it.
+func (s *S3Adapter) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	svc := s3.New(s.Session)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+
+	result, err := svc.GetObjectWithContext(ctx, input)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	return result.Body, nil
+}
+
+// objectReaderAt reads byte ranges of an object on demand.
+type objectReaderAt struct {
+	ctx     context.Context
+	adapter *S3Adapter
+	key     string
+}
+
+// ReaderAt returns an io.ReaderAt over an object that fetches each read with
+// a ranged GET, so only the bytes actually needed are downloaded.
+func (s *S3Adapter) ReaderAt(ctx context.Context, key string) io.ReaderAt {
+	return &objectReaderAt{ctx: ctx, adapter: s, key: key}
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	svc := s3.New(r.adapter.Session)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(r.adapter.Bucket),
+		Key:    aws.String(r.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1)),
+	}
+
+	result, err := svc.GetObjectWithContext(r.ctx, input)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusRequestedRangeNotSatisfiable {
+			return 0, io.EOF
+		}
+		return 0, err
+	}
+	defer result.Body.Close()
+
+	n, err := io.ReadFull(result.Body, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, io.EOF
+	}
+	return n, err
+}
+
 func (s *S3Adapter) PutObject(key string, data []byte) error {
 	svc := s3.New(s.Session)
 	input := &s3.PutObjectInput{
@@ -65,3 +153,130 @@ func (s *S3Adapter) PutObject(key string, data []byte) error {
 
 	return nil
 }
+
+// Upload streams body to S3 using a multipart upload, so memory use is bounded
+// by the part buffers rather than the size of the object.
+func (s *S3Adapter) Upload(ctx context.Context, key string, body io.Reader, contentType string) error {
+	uploader := s3manager.NewUploader(s.Session, func(u *s3manager.Uploader) {
+		u.PartSize = UploadPartSize
+		u.Concurrency = UploadConcurrency
+	})
+
+	input := &s3manager.UploadInput{
+		Body:   body,
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	_, err := uploader.UploadWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PresignPutObject returns a URL that lets a client PUT the object directly to
+// S3 until it expires. The client must send the same Content-Type header.
+func (s *S3Adapter) PresignPutObject(key string, contentType string, expires time.Duration) (string, error) {
+	svc := s3.New(s.Session)
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	req, _ := svc.PutObjectRequest(input)
+	return req.Presign(expires)
+}
+
+// PresignGetObject returns a URL that lets a client download the object until it expires.
+func (s *S3Adapter) PresignGetObject(key string, expires time.Duration) (string, error) {
+	svc := s3.New(s.Session)
+	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	return req.Presign(expires)
+}
+
+// HeadObject returns the metadata of an object, or ErrObjectNotFound.
+func (s *S3Adapter) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	svc := s3.New(s.Session)
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+
+	result, err := svc.HeadObjectWithContext(ctx, input)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Size:         aws.Int64Value(result.ContentLength),
+		ContentType:  aws.StringValue(result.ContentType),
+		LastModified: aws.TimeValue(result.LastModified),
+	}, nil
+}
+
+// CheckBucket checks that the bucket exists and can be accessed.
+func (s *S3Adapter) CheckBucket(ctx context.Context) error {
+	svc := s3.New(s.Session)
+	_, err := svc.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.Bucket)})
+	return err
+}
+
+// DeleteObjects removes the given keys. Keys that do not exist are ignored.
+func (s *S3Adapter) DeleteObjects(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	objects := make([]s3manager.BatchDeleteObject, len(keys))
+	for i, key := range keys {
+		objects[i] = s3manager.BatchDeleteObject{
+			Object: &s3.DeleteObjectInput{
+				Bucket: aws.String(s.Bucket),
+				Key:    aws.String(key),
+			},
+		}
+	}
+
+	batcher := s3manager.NewBatchDelete(s.Session)
+	return batcher.Delete(ctx, &s3manager.DeleteObjectsIterator{Objects: objects})
+}
+
+// DeletePrefix removes every object whose key starts with prefix.
+func (s *S3Adapter) DeletePrefix(ctx context.Context, prefix string) error {
+	svc := s3.New(s.Session)
+	iter := s3manager.NewDeleteListIterator(svc, &s3.ListObjectsInput{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	return s3manager.NewBatchDeleteWithClient(svc).Delete(ctx, iter)
+}
+
+// KeyFromURI returns the key of an s3:// URI in the adapter's bucket.
+func (s *S3Adapter) KeyFromURI(uri string) (string, bool) {
+	prefix := "s3://" + s.Bucket + "/"
+	if !strings.HasPrefix(uri, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(uri, prefix), true
+}
+
+// URI returns the s3:// URI of key in the adapter's bucket.
+func (s *S3Adapter) URI(key string) string {
+	return "s3://" + s.Bucket + "/" + key
+}
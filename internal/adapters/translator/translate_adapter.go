@@ -1,6 +1,8 @@
 package translator
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -47,3 +49,10 @@ func (ta *TranslateAdapter) TranslateText(text string, sourceLanguage string, ta
 		},
 	}, nil
 }
+
+// CheckHealth makes the cheapest call AWS Translate offers to check that it
+// can be reached with the configured credentials.
+func (ta *TranslateAdapter) CheckHealth(ctx context.Context) error {
+	_, err := ta.Client.ListLanguagesWithContext(ctx, &translate.ListLanguagesInput{MaxResults: aws.Int64(1)})
+	return err
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// ClassThresholdController lets admins override the minimum confidence and
+// frame count of single classes.
+type ClassThresholdController struct {
+	logger        *zap.Logger
+	thresholdRepo *database.ClassThresholdRepository
+	auditRepo     *database.AuditRepository
+}
+
+func NewClassThresholdController(thresholdRepo *database.ClassThresholdRepository, auditRepo *database.AuditRepository, logger *zap.Logger) *ClassThresholdController {
+	return &ClassThresholdController{
+		logger:        logger,
+		thresholdRepo: thresholdRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+// @Summary List class thresholds
+// @Description Lists the per-class overrides of the minimum average confidence and frame count. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.ClassThreshold}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/class-thresholds [get]
+func (c *ClassThresholdController) ListThresholds(ctx *gin.Context) {
+	thresholds, err := c.thresholdRepo.List()
+	if err != nil {
+		c.logger.Error("Error listing class thresholds", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing class thresholds"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": thresholds})
+}
+
+// @Summary Set a class threshold
+// @Description Overrides the minimum average confidence and/or frame count of a class. The override takes precedence over the request and default options. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   class path string true "Class name as returned by the model"
+// @Param   body body entity.ClassThresholdRequest true "Thresholds, leave a field out to keep the default"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.ClassThreshold}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/class-thresholds/{class} [put]
+func (c *ClassThresholdController) PutThreshold(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	class := strings.TrimSpace(ctx.Param("class"))
+	if class == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Class is required"})
+		return
+	}
+
+	var req entity.ClassThresholdRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MinAverage == nil && req.MinCount == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Set min_average or min_count, or delete the override"})
+		return
+	}
+
+	threshold := &entity.ClassThreshold{
+		Class:      class,
+		MinAverage: req.MinAverage,
+		MinCount:   req.MinCount,
+		UpdatedBy:  sub.(string),
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if err := c.thresholdRepo.Put(threshold); err != nil {
+		c.logger.Error("Error saving class threshold", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving class threshold"})
+		return
+	}
+
+	if err := c.auditRepo.Record(sub.(string), database.AuditPutThreshold, class, req); err != nil {
+		c.logger.Error("Failed to write audit entry", zap.String("action", database.AuditPutThreshold), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": threshold})
+}
+
+// @Summary Delete a class threshold
+// @Description Removes the override of a class so the request and default options apply again. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   class path string true "Class name as returned by the model"
+// @Success  204
+// @Failure  403 {object} map[string]interface{}
+// @Failure  404 {object} map[string]interface{}
+// @Router /admin/class-thresholds/{class} [delete]
+func (c *ClassThresholdController) DeleteThreshold(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	class := ctx.Param("class")
+	err := c.thresholdRepo.Delete(class)
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Class threshold not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error deleting class threshold", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting class threshold"})
+		return
+	}
+
+	if err := c.auditRepo.Record(sub.(string), database.AuditDeleteThreshold, class, nil); err != nil {
+		c.logger.Error("Failed to write audit entry", zap.String("action", database.AuditDeleteThreshold), zap.Error(err))
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/dataset"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// DatasetExportController lets admins export training datasets.
+type DatasetExportController struct {
+	logger    *zap.Logger
+	exporter  *dataset.Exporter
+	auditRepo *database.AuditRepository
+}
+
+func NewDatasetExportController(exporter *dataset.Exporter, auditRepo *database.AuditRepository, logger *zap.Logger) *DatasetExportController {
+	return &DatasetExportController{
+		logger:    logger,
+		exporter:  exporter,
+		auditRepo: auditRepo,
+	}
+}
+
+// @Summary Export a training dataset
+// @Description Writes a new versioned manifest of confirmed and corrected predictions to exports/{version}/ in the bucket, as manifest.jsonl and manifest.csv, and returns where it went.
+// @Description Each example has the video's S3 URI, its label and where the label came from, the segment timestamps, the user's consent and the model version. Only users who consented to training use are included unless include_unconsented is set. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   from query string false "Only feedback given at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param   to query string false "Only feedback given before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param   class query []string false "Only examples with these labels" collectionFormat(multi)
+// @Param   source query []string false "Only examples with these label sources" collectionFormat(multi) Enums(confirmed, corrected)
+// @Param   include_unconsented query bool false "Also export examples of users who did not consent"
+// @Success  201 {object} entity.ResponseWrapper{data=entity.DatasetExport}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/exports [post]
+func (c *DatasetExportController) CreateExport(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	filter := entity.DatasetFilter{Classes: ctx.QueryArray("class")}
+	var err error
+	if filter.From, err = parseTimeQuery(ctx, "from"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseTimeQuery(ctx, "to"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, source := range ctx.QueryArray("source") {
+		if source != string(entity.LabelConfirmed) && source != string(entity.LabelCorrected) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "source must be confirmed or corrected"})
+			return
+		}
+		filter.Sources = append(filter.Sources, entity.LabelSource(source))
+	}
+	if value := ctx.Query("include_unconsented"); value != "" {
+		if filter.IncludeUnconsented, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "include_unconsented must be a boolean"})
+			return
+		}
+	}
+
+	export, err := c.exporter.Export(ctx.Request.Context(), filter, sub.(string))
+	if err != nil {
+		c.logger.Error("Error exporting dataset", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while exporting dataset"})
+		return
+	}
+
+	detail := gin.H{"examples": export.Examples, "filter": filter}
+	if err := c.auditRepo.Record(sub.(string), database.AuditExportDataset, export.Version, detail); err != nil {
+		c.logger.Error("Failed to write audit entry", zap.String("action", database.AuditExportDataset), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": export})
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// FeedbackController collects users' corrections of predictions and hands
+// them to the ML team.
+type FeedbackController struct {
+	logger         *zap.Logger
+	predictionRepo *database.PredictionRepository
+	feedbackRepo   *database.FeedbackRepository
+}
+
+func NewFeedbackController(predictionRepo *database.PredictionRepository, feedbackRepo *database.FeedbackRepository, logger *zap.Logger) *FeedbackController {
+	return &FeedbackController{
+		logger:         logger,
+		predictionRepo: predictionRepo,
+		feedbackRepo:   feedbackRepo,
+	}
+}
+
+// @Summary Give feedback on a prediction
+// @Description Rates a prediction thumbs up or down and optionally names the sign that was actually shown, for the whole video or for the segment between start_seconds and end_seconds.
+// @Description A prediction can receive several pieces of feedback, e.g. one per segment.
+// @Tags api
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path int true "Prediction ID"
+// @Param   body body entity.FeedbackRequest true "Feedback"
+// @Success  201 {object} entity.ResponseWrapper{data=entity.Feedback}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  404 {object} map[string]interface{}
+// @Router /predictions/{id}/feedback [post]
+func (c *FeedbackController) CreateFeedback(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+
+	var req entity.FeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.StartSeconds != nil && req.EndSeconds != nil && *req.EndSeconds < *req.StartSeconds {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "end_seconds must not be before start_seconds"})
+		return
+	}
+
+	prediction, err := c.predictionRepo.Get(id, sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error reading prediction", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving feedback"})
+		return
+	}
+
+	feedback := entity.Feedback{
+		PredictionID:   prediction.ID,
+		Sub:            prediction.Sub,
+		VideoKey:       prediction.VideoKey,
+		Model:          prediction.Model,
+		Rating:         req.Rating,
+		PredictedClass: predictedClass(prediction, req.StartSeconds, req.EndSeconds),
+		CorrectClass:   strings.TrimSpace(req.CorrectClass),
+		StartSeconds:   req.StartSeconds,
+		EndSeconds:     req.EndSeconds,
+		Note:           strings.TrimSpace(req.Note),
+	}
+	if err := c.feedbackRepo.Create(&feedback); err != nil {
+		c.logger.Error("Error saving feedback", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving feedback"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": feedback})
+}
+
+// @Summary List feedback
+// @Description Lists the feedback of all users, oldest first. Pass next_cursor from the previous page as cursor to get newer feedback. When there is none yet the page is empty and next_cursor stays the same, so it can be polled. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   cursor query string false "Cursor from the previous page"
+// @Param   limit query int false "Page size (default 20, max 100)"
+// @Param   rating query string false "Only feedback with this rating" Enums(up, down)
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.Feedback}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/feedback [get]
+func (c *FeedbackController) ListFeedback(ctx *gin.Context) {
+	filter := entity.FeedbackFilter{Limit: defaultPageSize, Rating: entity.FeedbackRating(ctx.Query("rating"))}
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		after, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || after < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		filter.After = after
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageSize {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		filter.Limit = n
+	}
+	if filter.Rating != "" && filter.Rating != entity.FeedbackUp && filter.Rating != entity.FeedbackDown {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "rating must be up or down"})
+		return
+	}
+
+	feedback, err := c.feedbackRepo.List(filter)
+	if err != nil {
+		c.logger.Error("Error listing feedback", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing feedback"})
+		return
+	}
+
+	// Unlike other lists this one is followed from the oldest entry, so the
+	// cursor is the last entry returned even on a partial page
+	nextCursor := ctx.Query("cursor")
+	if len(feedback) > 0 {
+		nextCursor = strconv.FormatInt(feedback[len(feedback)-1].ID, 10)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": feedback, "next_cursor": nextCursor})
+}
+
+// predictedClass returns the class the feedback is about: the timeline
+// segment overlapping the given time range the most, else the top result.
+func predictedClass(prediction *entity.Prediction, start *float64, end *float64) string {
+	if start != nil || end != nil {
+		from, to := 0.0, math.Inf(1)
+		if start != nil {
+			from = *start
+		}
+		if end != nil {
+			to = *end
+		}
+
+		var class string
+		best := -1.0
+		for _, segment := range prediction.Timeline {
+			if segment.StartSeconds == nil || segment.EndSeconds == nil {
+				continue
+			}
+			overlap := math.Min(to, *segment.EndSeconds) - math.Max(from, *segment.StartSeconds)
+			if overlap >= 0 && overlap > best {
+				class, best = segment.Class, overlap
+			}
+		}
+		if class != "" {
+			return class
+		}
+	}
+
+	if len(prediction.Result) > 0 {
+		return prediction.Result[0].Class
+	}
+	return ""
+}
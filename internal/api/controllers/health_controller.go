@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthController shows operators the health of the API's dependencies.
+type HealthController struct {
+	monitor *HealthMonitor
+}
+
+func NewHealthController(monitor *HealthMonitor) *HealthController {
+	return &HealthController{monitor: monitor}
+}
+
+// @Summary Dependency health
+// @Description Returns the latest background check of every dependency: database, storage, translation and ML service. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.DependencyHealth}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/health [get]
+func (c *HealthController) GetHealth(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"data": c.monitor.Snapshot()})
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// Names of the dependencies checked by the health monitor.
+const (
+	DependencyDatabase  = "database"
+	DependencyStorage   = "storage"
+	DependencyTranslate = "translate"
+	DependencyML        = "ml"
+)
+
+// HealthCheck returns an error if a dependency cannot be used.
+type HealthCheck func(ctx context.Context) error
+
+type dependency struct {
+	name   string
+	check  HealthCheck
+	health entity.DependencyHealth
+}
+
+// HealthMonitor checks dependencies in the background, so requests can tell
+// whether a dependency is usable without waiting for it.
+type HealthMonitor struct {
+	logger   *zap.Logger
+	interval time.Duration
+	timeout  time.Duration
+
+	mu           sync.RWMutex
+	dependencies []*dependency
+}
+
+func NewHealthMonitor(interval time.Duration, timeout time.Duration, logger *zap.Logger) *HealthMonitor {
+	return &HealthMonitor{
+		logger:   logger,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Register adds a dependency to check. It must be called before Start.
+func (m *HealthMonitor) Register(name string, check HealthCheck) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dependencies = append(m.dependencies, &dependency{
+		name:   name,
+		check:  check,
+		health: entity.DependencyHealth{Name: name, Status: entity.DependencyUnknown},
+	})
+}
+
+// Start checks every dependency right away and then every interval until
+// ctx is cancelled.
+func (m *HealthMonitor) Start(ctx context.Context) {
+	m.mu.RLock()
+	dependencies := m.dependencies
+	m.mu.RUnlock()
+
+	for _, dep := range dependencies {
+		go m.watch(ctx, dep)
+	}
+}
+
+func (m *HealthMonitor) watch(ctx context.Context, dep *dependency) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.checkDependency(ctx, dep)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *HealthMonitor) checkDependency(ctx context.Context, dep *dependency) {
+	checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.check(checkCtx)
+	if ctx.Err() != nil {
+		return
+	}
+	checkedAt := time.Now().UTC()
+
+	status := entity.DependencyUp
+	message := ""
+	if err != nil {
+		status = entity.DependencyDown
+		message = err.Error()
+	}
+
+	m.mu.Lock()
+	previous := dep.health
+	dep.health.Status = status
+	dep.health.Error = message
+	dep.health.LatencyMs = time.Since(start).Milliseconds()
+	dep.health.CheckedAt = &checkedAt
+	if previous.Status != status {
+		dep.health.Since = &checkedAt
+	}
+	m.mu.Unlock()
+
+	switch {
+	case previous.Status == status:
+	case status == entity.DependencyDown:
+		m.logger.Warn("Dependency is down", zap.String("dependency", dep.name), zap.Error(err))
+	case previous.Status == entity.DependencyDown:
+		m.logger.Info("Dependency recovered", zap.String("dependency", dep.name))
+	}
+}
+
+// Healthy reports whether a dependency can be used. A dependency that was
+// not checked yet or is not registered is assumed to be usable.
+func (m *HealthMonitor) Healthy(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, dep := range m.dependencies {
+		if dep.name == name {
+			return dep.health.Status != entity.DependencyDown
+		}
+	}
+	return true
+}
+
+// RetryAfter is how long until a dependency that is down is checked again.
+func (m *HealthMonitor) RetryAfter() time.Duration {
+	return m.interval
+}
+
+// Snapshot returns the latest health of every dependency in the order they
+// were registered.
+func (m *HealthMonitor) Snapshot() []entity.DependencyHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	health := make([]entity.DependencyHealth, len(m.dependencies))
+	for i, dep := range m.dependencies {
+		health[i] = dep.health
+	}
+	return health
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// BatchLimits bound the number of videos in a batch and how many of them are
+// processed at the same time.
+type BatchLimits struct {
+	MaxFiles    int
+	Concurrency int
+}
+
+// @Summary Upload several videos for prediction
+// @Description Runs the prediction for every video part of the request, a few at a time, and returns one item per video in the order they were sent.
+// @Description A video that fails does not fail the others; its item carries the status, error and code it would have got on its own.
+// @Description With async=true a job is created for each video instead.
+// @Tags api
+// @Security BearerAuth
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   Idempotency-Key header string false "Unique key of this request; a retry with the same key gets the first response back instead of running again"
+// @Param   video formData []file true "Video files to upload, one part per video" collectionFormat(multi)
+// @Param   language formData string false "Target languages, comma separated. Defaults to the preferred Accept-Language, then the user's default language"
+// @Param   Accept-Language header string false "Preferred target language when no language is given"
+// @Param   top_k query int false "Maximum number of classes to return, 0 for all"
+// @Param   min_average query number false "Minimum average confidence of a returned class"
+// @Param   min_count query int false "Minimum number of frames a returned class was predicted in"
+// @Param   async query bool false "Create a job per video instead of waiting for the results"
+// @Param   X-ML-Backend header string false "ML backend to use instead of the routed one, admins only"
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.BatchPredictItem}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  409 {object} map[string]interface{}
+// @Failure  413 {object} map[string]interface{}
+// @Failure  503 {object} map[string]interface{}
+// @Header   503 {integer} Retry-After "Seconds until the ML service is checked again"
+// @Router /predict/batch [post]
+func (c *PredictController) PredictBatch(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	if c.videoLimits.MaxBytes > 0 {
+		maxBytes := c.videoLimits.MaxBytes*int64(c.batchLimits.MaxFiles) + multipartOverhead
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		c.logger.Error("batch multipart form failed", zap.Error(err))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Batch is too large", "code": validators.CodeBatchTooLarge})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No video file provided", "code": validators.CodeVideoMissing})
+		return
+	}
+
+	files := form.File["video"]
+	if len(files) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No video file provided", "code": validators.CodeVideoMissing})
+		return
+	}
+	if c.batchLimits.MaxFiles > 0 && len(files) > c.batchLimits.MaxFiles {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d videos can be sent in one batch", c.batchLimits.MaxFiles), "code": validators.CodeBatchTooLarge})
+		return
+	}
+
+	options, ok := c.requestOptions(ctx, sub.(string))
+	if !ok {
+		return
+	}
+	async := isAsync(ctx)
+
+	concurrency := c.batchLimits.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	items := make([]entity.BatchPredictItem, len(files))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file *multipart.FileHeader) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			items[i] = c.predictBatchItem(ctx.Request.Context(), sub.(string), file, options, async)
+			items[i].Index = i
+		}(i, file)
+	}
+	wg.Wait()
+
+	ctx.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// predictBatchItem runs the same steps as Predict for one video of a batch,
+// reporting failures in the returned item instead of the response.
+func (c *PredictController) predictBatchItem(ctx context.Context, sub string, file *multipart.FileHeader, options *predictOptions, async bool) entity.BatchPredictItem {
+	item := entity.BatchPredictItem{Filename: file.Filename}
+
+	if err := c.validateUpload(file); err != nil {
+		c.logger.Info("Rejected video upload", zap.String("filename", file.Filename), zap.Error(err))
+		var verr *validators.ValidationError
+		if !errors.As(err, &verr) {
+			verr = &validators.ValidationError{Status: http.StatusBadRequest, Code: validators.CodeVideoUnreadable, Message: "Error while processing the video"}
+		}
+		item.Status, item.Error, item.Code = verr.Status, verr.Message, verr.Code
+		return item
+	}
+
+	video, err := c.uploadVideoToS3(ctx, sub, file)
+	if err != nil {
+		c.logger.Error("Error uploading video to S3: ", zap.Error(err))
+		item.Status, item.Error = http.StatusBadRequest, "Error while processing the video"
+		return item
+	}
+
+	if err := c.insertToS3Table(sub, video.S3Link); err != nil {
+		c.logger.Error("Error inserting record into database: ", zap.Error(err))
+		item.Status, item.Error = http.StatusInternalServerError, "Error while inserting record into database"
+		return item
+	}
+
+	if async {
+		job, err := c.createJob(sub, video, options)
+		if err != nil {
+			item.Status, item.Error = predictErrorStatus(err)
+			return item
+		}
+		item.Status, item.Job = http.StatusAccepted, job
+		return item
+	}
+
+	prediction, err := c.runPrediction(ctx, sub, video, options, nil)
+	item.PredictionID = prediction.ID
+	if err != nil {
+		item.Status, item.Error = predictErrorStatus(err)
+		return item
+	}
+	item.Status = http.StatusOK
+	item.Model = prediction.Model
+	item.Result = prediction.Result
+	item.Timeline = prediction.Timeline
+	item.Sentence = prediction.Sentence
+	return item
+}
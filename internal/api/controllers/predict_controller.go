@@ -1,10 +1,18 @@
 package controllers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,6 +21,8 @@ import (
 	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
 	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
 	"github.com/Zeta-Manu/Backend/internal/adapters/translator"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
 	"github.com/Zeta-Manu/Backend/internal/domain/entity"
 	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
 )
@@ -23,21 +33,110 @@ type PredictController struct {
 	dbAdapter        database.DBAdapter
 	s3Adapter        s3.S3Adapter
 	translateAdapter translator.TranslateAdapter
-	mlService        httpadapter.MLService
+	mlRouter         *httpadapter.MLRouter
+	jobRepo          *database.PredictionJobRepository
+	cacheRepo        *database.PredictionCacheRepository
+	predictionRepo   *database.PredictionRepository
+	workerPool       *PredictionWorkerPool
+	videoLimits      validators.VideoLimits
+	languages        validators.Languages
+	defaultLanguage  string
+	settingsRepo     *database.UserSettingsRepository
+	resultDefaults   entity.ResultOptions
+	thresholdRepo    *database.ClassThresholdRepository
+	sentenceOptions  entity.SentenceOptions
+	batchLimits      BatchLimits
+	mlStream         httpadapter.MLStreamService
+	streamLimits     StreamLimits
+	webhooks         *WebhookDispatcher
+	progress         *ProgressBroker
+	shadow           *ShadowRunner
 }
 
-func NewPredictController(dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, translateAdapter translator.TranslateAdapter, mlService httpadapter.MLService, logger *zap.Logger) *PredictController {
+// sourceLanguage is the language of the class names returned by the ML API.
+const sourceLanguage = "en"
+
+// multipartOverhead is the allowance for multipart headers and other form
+// fields on top of the maximum video size.
+const multipartOverhead = 1 << 20
+
+func NewPredictController(dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, translateAdapter translator.TranslateAdapter, mlRouter *httpadapter.MLRouter, jobRepo *database.PredictionJobRepository, cacheRepo *database.PredictionCacheRepository, predictionRepo *database.PredictionRepository, workerPool *PredictionWorkerPool, videoLimits validators.VideoLimits, languages validators.Languages, defaultLanguage string, settingsRepo *database.UserSettingsRepository, resultDefaults entity.ResultOptions, thresholdRepo *database.ClassThresholdRepository, sentenceOptions entity.SentenceOptions, batchLimits BatchLimits, mlStream httpadapter.MLStreamService, streamLimits StreamLimits, webhooks *WebhookDispatcher, progress *ProgressBroker, shadow *ShadowRunner, logger *zap.Logger) *PredictController {
 	return &PredictController{
 		dbAdapter:        dbAdapter,
 		s3Adapter:        s3Adapter,
 		translateAdapter: translateAdapter,
 		logger:           logger,
-		mlService:        mlService,
+		mlRouter:         mlRouter,
+		jobRepo:          jobRepo,
+		cacheRepo:        cacheRepo,
+		predictionRepo:   predictionRepo,
+		workerPool:       workerPool,
+		videoLimits:      videoLimits,
+		languages:        languages,
+		defaultLanguage:  defaultLanguage,
+		settingsRepo:     settingsRepo,
+		resultDefaults:   resultDefaults,
+		thresholdRepo:    thresholdRepo,
+		sentenceOptions:  sentenceOptions,
+		batchLimits:      batchLimits,
+		mlStream:         mlStream,
+		streamLimits:     streamLimits,
+		webhooks:         webhooks,
+		progress:         progress,
+		shadow:           shadow,
 	}
 }
 
+// uploadedVideo identifies an uploaded video in S3.
+type uploadedVideo struct {
+	Key            string
+	S3Link         string
+	ContentHash    string
+	UploadDuration time.Duration
+}
+
+// predictOptions are the per-request choices of how a prediction is presented.
+type predictOptions struct {
+	Languages []string
+	Result    entity.ResultOptions
+	// Backend is the ML backend an admin asked for, empty to let the
+	// router choose
+	Backend string
+}
+
+// progressFunc is called when a prediction enters a pipeline stage.
+type progressFunc func(stage entity.PredictionStage)
+
+// predictError carries the client-facing message and status code of a
+// failed pipeline step alongside the underlying error.
+type predictError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *predictError) Error() string {
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *predictError) Unwrap() error {
+	return e.err
+}
+
+// predictErrorStatus returns the status and client-facing message of a
+// failed pipeline step. Errors that are not a *predictError get a generic
+// message so nothing internal reaches the client.
+func predictErrorStatus(err error) (int, string) {
+	var perr *predictError
+	if errors.As(err, &perr) {
+		return perr.status, perr.message
+	}
+	return http.StatusInternalServerError, "Error while processing the video"
+}
+
 // @Summary Upload a video for prediction
-// @Description Uploads a video file to S3 and prepares it for machine learning prediction
+// @Description Uploads a video file to S3 and prepares it for machine learning prediction.
+// @Description With async=true the prediction runs in the background and a job is returned right away. Its progress can be followed through GET /predict/{id}/events.
 // @Tags api
 // @Security BearerAuth
 // @SecurityDefinition BearerAuth
@@ -47,116 +146,645 @@ func NewPredictController(dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter,
 // @Accept  multipart/form-data
 // @Produce  json
 // @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   Idempotency-Key header string false "Unique key of this request; a retry with the same key gets the first response back instead of running again"
 // @Param   video formData file true "Video file to upload"
+// @Param   language formData string false "Target languages, comma separated. Defaults to the preferred Accept-Language, then the user's default language"
+// @Param   Accept-Language header string false "Preferred target language when no language is given"
+// @Param   top_k query int false "Maximum number of classes to return, 0 for all"
+// @Param   min_average query number false "Minimum average confidence of a returned class"
+// @Param   min_count query int false "Minimum number of frames a returned class was predicted in"
+// @Param   async query bool false "Return a job ID instead of waiting for the result"
+// @Param   X-ML-Backend header string false "ML backend to use instead of the routed one, admins only"
 // @Success  200 {object} map[string]interface{}
+// @Success  202 {object} entity.ResponseWrapper{data=entity.PredictionJob}
 // @Failure  400 {object} map[string]interface{}
+// @Failure  409 {object} map[string]interface{}
+// @Failure  413 {object} map[string]interface{}
+// @Failure  415 {object} map[string]interface{}
+// @Failure  422 {object} map[string]interface{}
+// @Failure  502 {object} map[string]interface{}
+// @Failure  503 {object} map[string]interface{}
+// @Header   503 {integer} Retry-After "Seconds until the ML service is tried again"
+// @Failure  504 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /predict [post]
 func (c *PredictController) Predict(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		c.logger.Error("Cannot get subject")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	// Stop reading oversized bodies before they are spooled to disk
+	if c.videoLimits.MaxBytes > 0 {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.videoLimits.MaxBytes+multipartOverhead)
+	}
+
 	// Get the uploaded video file from the request
 	file, err := ctx.FormFile("video")
 	if err != nil {
 		c.logger.Error("video form file failed", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No video file provided"})
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondValidationError(ctx, c.videoLimits.TooLarge())
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No video file provided", "code": validators.CodeVideoMissing})
+		return
+	}
+
+	if err := c.validateUpload(file); err != nil {
+		c.logger.Info("Rejected video upload", zap.String("filename", file.Filename), zap.Error(err))
+		respondValidationError(ctx, err)
 		return
 	}
 
-	s3Link, err := c.uploadVideoToS3(&c.s3Adapter.Bucket, file)
+	options, ok := c.requestOptions(ctx, sub.(string))
+	if !ok {
+		return
+	}
+
+	video, err := c.uploadVideoToS3(ctx.Request.Context(), sub.(string), file)
 	if err != nil {
 		c.logger.Error("Error uploading video to S3: ", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while processing the video"})
 		return
 	}
 
+	// Insert a record into the database
+	err = c.insertToS3Table(sub.(string), video.S3Link)
+	if err != nil {
+		c.logger.Error("Error inserting record into database: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting record into database"})
+		return
+	}
+
+	c.predictStored(ctx, sub.(string), video, options)
+}
+
+// @Summary Predict a directly uploaded video
+// @Description Runs the prediction for a video uploaded through a URL from POST /uploads
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   Idempotency-Key header string false "Unique key of this request; a retry with the same key gets the first response back instead of running again"
+// @Param   key path string true "Upload ID returned by POST /uploads"
+// @Param   language query string false "Target languages, comma separated. Defaults to the preferred Accept-Language, then the user's default language"
+// @Param   Accept-Language header string false "Preferred target language when no language is given"
+// @Param   top_k query int false "Maximum number of classes to return, 0 for all"
+// @Param   min_average query number false "Minimum average confidence of a returned class"
+// @Param   min_count query int false "Minimum number of frames a returned class was predicted in"
+// @Param   async query bool false "Return a job ID instead of waiting for the result"
+// @Param   X-ML-Backend header string false "ML backend to use instead of the routed one, admins only"
+// @Success  200 {object} map[string]interface{}
+// @Success  202 {object} entity.ResponseWrapper{data=entity.PredictionJob}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  404 {object} map[string]interface{}
+// @Failure  409 {object} map[string]interface{}
+// @Failure  413 {object} map[string]interface{}
+// @Failure  415 {object} map[string]interface{}
+// @Failure  422 {object} map[string]interface{}
+// @Failure  502 {object} map[string]interface{}
+// @Failure  503 {object} map[string]interface{}
+// @Header   503 {integer} Retry-After "Seconds until the ML service is tried again"
+// @Failure  504 {object} map[string]interface{}
+// @Router /predict/{key} [post]
+func (c *PredictController) PredictUploaded(ctx *gin.Context) {
 	sub, exists := ctx.Get("sub")
 	if !exists {
-		c.logger.Error("Cannot get subject", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
 		return
 	}
 
-	// Insert a record into the database
-	err = c.insertToS3Table(sub.(string), s3Link)
+	uploadID := ctx.Param("key")
+	if !uploadIDPattern.MatchString(uploadID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload key"})
+		return
+	}
+
+	options, ok := c.requestOptions(ctx, sub.(string))
+	if !ok {
+		return
+	}
+
+	// The key is always resolved under the caller's own prefix, so an upload
+	// of another user can never be referenced
+	key := uploadKey(sub.(string), uploadID)
+	object, err := c.s3Adapter.HeadObject(ctx.Request.Context(), key)
 	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+			return
+		}
+		c.logger.Error("Error reading uploaded object", zap.String("key", key), zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading the uploaded video"})
+		return
+	}
+
+	_, err = validators.ValidateVideo(c.s3Adapter.ReaderAt(ctx.Request.Context(), key), object.Size, c.videoLimits)
+	if err != nil {
+		c.logger.Info("Rejected uploaded video", zap.String("key", key), zap.Error(err))
+		// Rejected uploads are not kept around
+		if err := c.s3Adapter.DeleteObjects(ctx.Request.Context(), []string{key}); err != nil {
+			c.logger.Warn("Failed to delete rejected upload", zap.String("key", key), zap.Error(err))
+		}
+		respondValidationError(ctx, err)
+		return
+	}
+
+	contentHash, err := c.hashObject(ctx.Request.Context(), key)
+	if err != nil {
+		c.logger.Error("Error hashing uploaded object", zap.String("key", key), zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading the uploaded video"})
+		return
+	}
+
+	video := &uploadedVideo{Key: key, S3Link: c.s3Adapter.URI(key), ContentHash: contentHash}
+	if err := c.insertToS3Table(sub.(string), video.S3Link); err != nil {
 		c.logger.Error("Error inserting record into database: ", zap.Error(err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting record into database"})
 		return
 	}
 
+	c.predictStored(ctx, sub.(string), video, options)
+}
+
+// predictStored runs the prediction for a video already stored in S3, either
+// inline or as a background job when the client asked for it.
+func (c *PredictController) predictStored(ctx *gin.Context, sub string, video *uploadedVideo, options *predictOptions) {
+	if isAsync(ctx) {
+		c.enqueuePrediction(ctx, sub, video, options)
+		return
+	}
+
+	prediction, err := c.runPrediction(ctx.Request.Context(), sub, video, options, nil)
+	if err != nil {
+		respondPredictError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, predictionResponse(prediction))
+}
+
+// predictionResponse is the body of a successful synchronous prediction.
+func predictionResponse(prediction *entity.Prediction) gin.H {
+	return gin.H{"prediction_id": prediction.ID, "model": prediction.Model, "result": prediction.Result, "timeline": prediction.Timeline, "sentence": prediction.Sentence}
+}
+
+// @Summary Get a prediction job
+// @Description Reports the status of an asynchronous prediction job and its result once it succeeded
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path string true "Job ID"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.PredictionJob}
+// @Failure  404 {object} map[string]interface{}
+// @Router /predict/jobs/{id} [get]
+func (c *PredictController) GetJob(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	job, err := c.jobRepo.Get(ctx.Param("id"), sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error reading prediction job", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading prediction job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// ProcessJob runs the prediction pipeline for a claimed job and stores the
+// outcome. A job interrupted by ctx is put back into the queue.
+func (c *PredictController) ProcessJob(ctx context.Context, job *entity.PredictionJob) {
+	video := &uploadedVideo{Key: job.VideoKey, S3Link: job.S3Link, ContentHash: job.ContentHash}
+	options := &predictOptions{Languages: job.Languages, Result: c.resultDefaults}
+	if len(options.Languages) == 0 {
+		// Jobs queued before target languages were configurable
+		options.Languages = []string{c.defaultLanguage}
+	}
+	if job.Options != nil {
+		options.Result = *job.Options
+	}
+	options.Backend = job.MLBackend
+	report := func(stage entity.PredictionStage) {
+		c.progress.Publish(job.ID, stage, 0, nil, "")
+	}
+	prediction, err := c.runPrediction(ctx, job.Sub, video, options, report)
+	if err != nil && ctx.Err() != nil {
+		if err := c.jobRepo.Requeue(job.ID); err != nil {
+			c.logger.Error("Failed to requeue interrupted prediction job", zap.String("job", job.ID), zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		_, message := predictErrorStatus(err)
+		if err := c.jobRepo.MarkFailed(job.ID, prediction.ID, message); err != nil {
+			c.logger.Error("Failed to mark prediction job as failed", zap.String("job", job.ID), zap.Error(err))
+		}
+		c.progress.Publish(job.ID, entity.StageError, 0, nil, message)
+		return
+	}
+
+	if err := c.jobRepo.MarkSucceeded(job.ID, prediction); err != nil {
+		c.logger.Error("Failed to store prediction job result", zap.String("job", job.ID), zap.Error(err))
+	}
+	c.progress.Publish(job.ID, entity.StageDone, 0, predictionResponse(prediction), "")
+}
+
+func (c *PredictController) enqueuePrediction(ctx *gin.Context, sub string, video *uploadedVideo, options *predictOptions) {
+	job, err := c.createJob(sub, video, options)
+	if err != nil {
+		respondPredictError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"data": job})
+}
+
+// createJob stores a prediction job and hands it to the worker pool.
+func (c *PredictController) createJob(sub string, video *uploadedVideo, options *predictOptions) (*entity.PredictionJob, error) {
+	job := &entity.PredictionJob{Sub: sub, VideoKey: video.Key, S3Link: video.S3Link, ContentHash: video.ContentHash, Languages: options.Languages, Options: &options.Result, MLBackend: options.Backend}
+	if err := c.jobRepo.Create(job); err != nil {
+		c.logger.Error("Error creating prediction job", zap.Error(err))
+		return nil, &predictError{http.StatusInternalServerError, "Error while creating prediction job", err}
+	}
+	c.progress.Publish(job.ID, entity.StageUploaded, video.UploadDuration, nil, "")
+	c.progress.Publish(job.ID, entity.StageStored, 0, nil, "")
+
+	if err := c.workerPool.Enqueue(job.ID); err != nil {
+		c.logger.Error("Error enqueuing prediction job", zap.String("job", job.ID), zap.Error(err))
+		if err := c.jobRepo.MarkFailed(job.ID, 0, "Prediction queue is full"); err != nil {
+			c.logger.Error("Failed to mark prediction job as failed", zap.String("job", job.ID), zap.Error(err))
+		}
+		c.progress.Publish(job.ID, entity.StageError, 0, nil, "Prediction queue is full")
+		return nil, &predictError{http.StatusServiceUnavailable, "Prediction queue is full, try again later", err}
+	}
+	return job, nil
+}
+
+// runPrediction runs the prediction for an uploaded video and records the
+// outcome, successful or not, in the predictions table. A prediction
+// interrupted by ctx is not recorded. The returned prediction is never nil.
+// report, if not nil, is told about each stage.
+func (c *PredictController) runPrediction(ctx context.Context, sub string, video *uploadedVideo, options *predictOptions, report progressFunc) (*entity.Prediction, error) {
+	if report == nil {
+		report = func(entity.PredictionStage) {}
+	}
+	started := time.Now()
+	prediction := &entity.Prediction{
+		Sub:         sub,
+		VideoKey:    video.Key,
+		ContentHash: video.ContentHash,
+		Model:       options.Backend,
+		Timings:     entity.PredictionTimings{UploadMs: video.UploadDuration.Milliseconds()},
+	}
+
+	backend, err := c.mlRouter.Select(sub, options.Backend)
+	if err != nil {
+		// Only possible for a job whose backend was removed since it was queued
+		err = &predictError{http.StatusBadRequest, "Unknown ML backend", err}
+	} else {
+		prediction.Model = backend.Name
+		route := &httpadapter.MLRoute{Sub: sub, Backend: options.Backend, Model: backend.Name}
+		err = c.predict(ctx, route, video, options, prediction, report)
+	}
+	prediction.Timings.TotalMs = prediction.Timings.UploadMs + time.Since(started).Milliseconds()
+	if err != nil && ctx.Err() != nil {
+		// Interrupted, not failed: a job is put back into the queue and a
+		// client that went away gets no answer, so nothing is recorded
+		return prediction, err
+	}
+	if err != nil {
+		// The error itself may quote the ML service, so only the message meant
+		// for the user is stored
+		c.logger.Warn("Prediction failed", zap.String("key", video.Key), zap.Error(err))
+		prediction.Status = entity.PredictionFailed
+		_, prediction.Error = predictErrorStatus(err)
+	} else {
+		prediction.Status = entity.PredictionSucceeded
+	}
+
+	if err := c.predictionRepo.Create(prediction); err != nil {
+		c.logger.Error("Failed to record prediction", zap.String("key", video.Key), zap.Error(err))
+	} else if c.shadow != nil && prediction.Status == entity.PredictionSucceeded {
+		c.shadow.Run(prediction, video.S3Link)
+	}
+	c.notifyWebhooks(prediction)
+	return prediction, err
+}
+
+// predict sends an uploaded video to the ML API, or reuses the result of an
+// identical video, ranks the predicted classes and assembles the signs into a
+// sentence, translating both into each target language and filling in
+// prediction as it goes. The backend that answered is recorded as the model.
+func (c *PredictController) predict(ctx context.Context, route *httpadapter.MLRoute, video *uploadedVideo, options *predictOptions, prediction *entity.Prediction, report progressFunc) error {
+	report(entity.StageInferring)
+	inferStarted := time.Now()
+
 	// Send the video to the ML API
-	infer, err := c.sendToML(s3Link)
+	infer, cached, err := c.sendToML(ctx, route, video)
+	prediction.Model = route.Model
 	if err != nil {
 		c.logger.Error("Error sending video to ML API: ", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while sending video to ML API"})
-		return
+		status, message := mlErrorStatus(err)
+		return &predictError{status, message, err}
 	}
 
 	// Process the returned data from SageMaker
-	avg, err := c.processMLResult(infer)
+	response, avg, err := c.processMLResult(infer)
 	if err != nil {
 		c.logger.Error("Error processing ML result: ", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error processing ML result"})
-		return
+		return &predictError{http.StatusBadGateway, "The ML service returned an invalid result", err}
 	}
+	prediction.Timings.InferenceMs = time.Since(inferStarted).Milliseconds()
+	prediction.Cached = cached
+	prediction.Raw = response.Results.Raw
+	prediction.Timeline = buildTimeline(response.Results.Raw, response.Results.FPS)
+	prediction.Avg = response.Results.Avg
 
+	if !cached && video.ContentHash != "" {
+		if err := c.cacheRepo.Put(video.ContentHash, route.Model, infer); err != nil {
+			c.logger.Warn("Failed to cache ML result", zap.String("hash", video.ContentHash), zap.Error(err))
+		}
+	}
+
+	overrides, err := c.thresholdRepo.ByClass()
+	if err != nil {
+		// Better to fall back to the request options than to fail the prediction
+		c.logger.Warn("Failed to read class thresholds", zap.Error(err))
+	}
+	avg = rankClasses(avg, options.Result, overrides)
+
+	report(entity.StageTranslating)
+	translateStarted := time.Now()
 	classes := getKeysFromProcessedAvgs(avg)
 	responses := make([]entity.PredictResponse, len(classes))
 
 	for i, class := range classes {
 		// Translate the processed data
-		translatedData, err := c.translateData(class, "TH")
-		if err != nil {
-			c.logger.Error("Error translating data: ", zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error translating data"})
-			return
+		translations := make(map[string]string, len(options.Languages))
+		for _, language := range options.Languages {
+			translatedData, err := c.translateData(class, language)
+			if err != nil {
+				c.logger.Error("Error translating data: ", zap.String("language", language), zap.Error(err))
+				return &predictError{http.StatusInternalServerError, "Error translating data", err}
+			}
+			translations[language] = *translatedData
 		}
 		average := avg[i].Average
 		sum := avg[i].Sum
 		count := avg[i].Count
 
 		responses[i] = entity.PredictResponse{
-			Class:      class,
-			Translated: *translatedData,
-			Average:    average,
-			Sum:        sum,
-			Count:      count,
+			Class:        class,
+			Translations: translations,
+			Average:      average,
+			Sum:          sum,
+			Count:        count,
+		}
+	}
+	prediction.Result = responses
+
+	if glosses := assembleGlosses(prediction.Timeline, c.sentenceOptions); len(glosses) > 0 {
+		sentence := &entity.Sentence{
+			Glosses:      glosses,
+			Text:         glossText(glosses),
+			Translations: make(map[string]string, len(options.Languages)),
+		}
+		// The whole sentence is translated at once so the translation can
+		// use the context of every sign
+		for _, language := range options.Languages {
+			translatedData, err := c.translateData(sentence.Text, language)
+			if err != nil {
+				c.logger.Error("Error translating sentence: ", zap.String("language", language), zap.Error(err))
+				return &predictError{http.StatusInternalServerError, "Error translating sentence", err}
+			}
+			sentence.Translations[language] = *translatedData
 		}
+		prediction.Sentence = sentence
+	}
+	prediction.Timings.TranslateMs = time.Since(translateStarted).Milliseconds()
+
+	return nil
+}
+
+// notifyWebhooks tells the user's webhooks that a prediction finished. The
+// raw frames are left out of the payload as the timeline summarises them.
+func (c *PredictController) notifyWebhooks(prediction *entity.Prediction) {
+	event := entity.WebhookPredictionSucceeded
+	if prediction.Status == entity.PredictionFailed {
+		event = entity.WebhookPredictionFailed
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"result": responses})
+	data := *prediction
+	data.Raw = nil
+	c.webhooks.Notify(prediction.Sub, event, data)
 }
 
-func (c *PredictController) uploadVideoToS3(bucketName *string, file *multipart.FileHeader) (string, error) {
+// validateUpload checks a multipart video against the configured limits.
+func (c *PredictController) validateUpload(file *multipart.FileHeader) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = validators.ValidateVideo(f, file.Size, c.videoLimits)
+	return err
+}
+
+// respondValidationError reports a rejected video with its specific status
+// and error code.
+func respondValidationError(ctx *gin.Context, err error) {
+	var verr *validators.ValidationError
+	if errors.As(err, &verr) {
+		ctx.JSON(verr.Status, gin.H{"error": verr.Message, "code": verr.Code})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while processing the video", "code": validators.CodeVideoUnreadable})
+}
+
+// requestOptions reads the target languages and result options of a
+// prediction request. It responds with an error and returns false when they
+// are invalid.
+func (c *PredictController) requestOptions(ctx *gin.Context, sub string) (*predictOptions, bool) {
+	languages, err := c.targetLanguages(ctx, sub)
+	if err != nil {
+		respondValidationError(ctx, err)
+		return nil, false
+	}
+
+	result, err := parseResultOptions(ctx, c.resultDefaults)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	backend := middleware.ForcedMLBackend(ctx)
+	if backend != "" && !c.mlRouter.Has(backend) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown ML backend " + backend})
+		return nil, false
+	}
+	return &predictOptions{Languages: languages, Result: result, Backend: backend}, true
+}
+
+// targetLanguages returns the languages to translate predictions into: the
+// explicitly requested ones, else the preferred Accept-Language, else the
+// user's default language, else the configured default.
+func (c *PredictController) targetLanguages(ctx *gin.Context, sub string) ([]string, error) {
+	requested := ctx.PostFormArray("language")
+	if len(requested) == 0 {
+		requested = ctx.QueryArray("language")
+	}
+	languages, err := c.languages.Parse(requested)
+	if err != nil || len(languages) > 0 {
+		return languages, err
+	}
+
+	if language := c.languages.FromAcceptLanguage(ctx.GetHeader("Accept-Language")); language != "" {
+		return []string{language}, nil
+	}
+
+	settings, err := c.settingsRepo.Get(sub)
+	if err == nil && settings.DefaultLanguage != "" {
+		return []string{settings.DefaultLanguage}, nil
+	}
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		c.logger.Warn("Failed to read user settings", zap.Error(err))
+	}
+	return []string{c.defaultLanguage}, nil
+}
+
+// mlErrorStatus returns the status code and client-facing message for a
+// failed ML service call.
+func mlErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, httpadapter.ErrMLBadInput):
+		return http.StatusUnprocessableEntity, "The ML service could not process the video"
+	case errors.Is(err, httpadapter.ErrMLTimeout):
+		return http.StatusGatewayTimeout, "The ML service did not respond in time"
+	case errors.Is(err, httpadapter.ErrMLCircuitOpen):
+		return http.StatusServiceUnavailable, "The ML service is temporarily unavailable, try again later"
+	case errors.Is(err, httpadapter.ErrMLUnavailable):
+		return http.StatusServiceUnavailable, "The ML service is unavailable, try again later"
+	case errors.Is(err, httpadapter.ErrMLMalformedResponse):
+		return http.StatusBadGateway, "The ML service returned an invalid result"
+	default:
+		return http.StatusInternalServerError, "Error while sending video to ML API"
+	}
+}
+
+// respondPredictError reports a failed pipeline step with its status and
+// client-facing message. Clients are told when to come back if the ML
+// service is being given time to recover.
+func respondPredictError(ctx *gin.Context, err error) {
+	status, message := predictErrorStatus(err)
+	var open *httpadapter.CircuitOpenError
+	if errors.As(err, &open) {
+		ctx.Header("Retry-After", strconv.Itoa(open.RetryAfterSeconds()))
+	}
+	ctx.JSON(status, gin.H{"error": message})
+}
+
+// isAsync reports whether the client asked for the prediction to run in the background.
+func isAsync(ctx *gin.Context) bool {
+	if strings.EqualFold(ctx.GetHeader("Prefer"), "respond-async") {
+		return true
+	}
+	async, _ := strconv.ParseBool(ctx.DefaultQuery("async", ctx.PostForm("async")))
+	return async
+}
+
+func (c *PredictController) uploadVideoToS3(ctx context.Context, sub string, file *multipart.FileHeader) (*uploadedVideo, error) {
+	started := time.Now()
+
 	// Open the file
 	uploadedFile, err := file.Open()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer uploadedFile.Close()
 
-	// Read the file data
-	fileData, err := io.ReadAll(uploadedFile)
+	// Hash the content so the key is unique per user and video
+	hash := sha256.New()
+	if _, err := io.Copy(hash, uploadedFile); err != nil {
+		return nil, err
+	}
+	if _, err := uploadedFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	contentHash := hex.EncodeToString(hash.Sum(nil))
+	key := videoKey(sub, contentHash, file.Filename)
+
+	video := &uploadedVideo{Key: key, S3Link: c.s3Adapter.URI(key), ContentHash: contentHash}
+
+	// The same user already uploaded this exact video
+	_, err = c.s3Adapter.HeadObject(ctx, key)
+	if err == nil {
+		video.UploadDuration = time.Since(started)
+		return video, nil
+	}
+	if !errors.Is(err, s3.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	// Stream the file to S3
+	err = c.s3Adapter.Upload(ctx, key, uploadedFile, file.Header.Get("Content-Type"))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	video.UploadDuration = time.Since(started)
+	return video, nil
+}
 
-	// Upload the file to S3
-	err = c.s3Adapter.PutObject(file.Filename, fileData)
+// hashObject computes the SHA-256 of an object already stored in S3 by
+// streaming it, which is far cheaper than running inference on it again.
+func (c *PredictController) hashObject(ctx context.Context, key string) (string, error) {
+	body, err := c.s3Adapter.OpenObject(ctx, key)
 	if err != nil {
 		return "", err
 	}
-	// Construct the S3 URL
-	s3Link := "s3://" + *bucketName + "/" + file.Filename
-	// Debug: Log the constructed S3 URL
-	return s3Link, nil
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// videoKey returns the content-addressed object key of an uploaded video.
+func videoKey(sub string, contentHash string, filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	if !extensionPattern.MatchString(ext) {
+		ext = ".mp4"
+	}
+	return videoPrefix(sub) + contentHash + ext
+}
+
+// videoPrefix returns the prefix holding all videos uploaded through the API by a user.
+func videoPrefix(sub string) string {
+	return "videos/" + sub + "/"
 }
 
 func (c *PredictController) insertToS3Table(sub string, s3Link string) error {
-	// SQL query to insert a new record into the database
-	query := "INSERT INTO S3_Table (sub, s3_links) VALUES (?, JSON_ARRAY(?))ON DUPLICATE KEY UPDATE s3_links = JSON_ARRAY_APPEND(COALESCE(s3_links, JSON_ARRAY()), '$', ?);"
+	// SQL query to insert a new record into the database. Videos are stored
+	// by content, so a resent video has the same link and is not added twice
+	query := "INSERT INTO S3_Table (sub, s3_links) VALUES (?, JSON_ARRAY(?))ON DUPLICATE KEY UPDATE s3_links = IF(JSON_CONTAINS(COALESCE(s3_links, JSON_ARRAY()), JSON_QUOTE(?)), s3_links, JSON_ARRAY_APPEND(COALESCE(s3_links, JSON_ARRAY()), '$', ?));"
 
 	// Execute the query with the filename and status
-	_, err := c.dbAdapter.Exec(query, sub, s3Link, s3Link)
+	_, err := c.dbAdapter.Exec(query, sub, s3Link, s3Link, s3Link)
 	if err != nil {
 		c.logger.Error("Failed to insert to db", zap.Error(err))
 		return err
@@ -166,39 +794,47 @@ func (c *PredictController) insertToS3Table(sub string, s3Link string) error {
 	return nil
 }
 
-func (c *PredictController) sendToML(s3Link string) ([]byte, error) {
+// sendToML returns the raw response for a video, from the ML router or, if
+// an identical video was sent to the model of route before, from the
+// prediction cache, and whether it was cached.
+func (c *PredictController) sendToML(ctx context.Context, route *httpadapter.MLRoute, video *uploadedVideo) ([]byte, bool, error) {
+	if video.ContentHash != "" {
+		result, err := c.cacheRepo.Get(video.ContentHash, route.Model)
+		if err == nil {
+			c.logger.Info("Reusing cached ML result", zap.String("hash", video.ContentHash), zap.String("model", route.Model))
+			return result, true, nil
+		}
+		if !errors.Is(err, database.ErrNotFound) {
+			c.logger.Warn("Failed to read prediction cache", zap.Error(err))
+		}
+	}
+
 	// Directly call the Predict method without using a goroutine
-	result, err := c.mlService.Predict(s3Link)
+	result, err := c.mlRouter.Predict(httpadapter.WithMLRoute(ctx, route), video.S3Link)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return result, nil
+	return result, false, nil
 }
 
-func (c *PredictController) processMLResult(infer []byte) ([]entity.ProcessedAvg, error) {
+func (c *PredictController) processMLResult(infer []byte) (*valueobjects.MlResponse, []entity.ProcessedAvg, error) {
 	var response valueobjects.MlResponse
 	err := json.Unmarshal(infer, &response)
 	if err != nil {
 		c.logger.Error("Failed Unmarshal MlResponse", zap.Error(err))
-		return nil, err
+		return nil, nil, err
 	}
 
-	processedAvgs := make([]entity.ProcessedAvg, 0, len(response.Results.Avg))
-	for key, value := range response.Results.Avg {
-		processedAvgs = append(processedAvgs, entity.ProcessedAvg{
-			Key:     key,
-			Average: value.Average,
-			Sum:     value.Sum,
-			Count:   value.Count,
-		})
-	}
-	return processedAvgs, nil
+	return &response, processedAvgs(response.Results.Avg), nil
 }
 
 func (c *PredictController) translateData(data string, targetLanguage string) (*string, error) {
-	const SOURCELANGUAGE = "en"
-	translatedText, err := c.translateAdapter.TranslateText(data, SOURCELANGUAGE, targetLanguage)
+	if strings.EqualFold(targetLanguage, sourceLanguage) {
+		return &data, nil
+	}
+
+	translatedText, err := c.translateAdapter.TranslateText(data, sourceLanguage, targetLanguage)
 	// Check for errors
 	if err != nil {
 		c.logger.Error("Cannot translate text input", zap.Error(err))
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// jobPollInterval is how often an event stream re-reads its job, both as a
+// keep-alive and to notice jobs finished by another process.
+const jobPollInterval = 5 * time.Second
+
+// @Summary Stream prediction progress
+// @Description Streams Server-Sent Events for an asynchronous prediction job as it moves through the uploaded, stored, inferring and translating stages, ending with done or error.
+// @Description Each event carries its timing; done carries the same data as the synchronous predict response. Events already sent are replayed on connect, after Last-Event-ID if given.
+// @Tags api
+// @Security BearerAuth
+// @Produce  text/event-stream
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path string true "Job ID returned by POST /predict?async=true"
+// @Param   Last-Event-ID header string false "ID of the last event received before reconnecting"
+// @Success  200 {object} entity.ProgressEvent
+// @Failure  404 {object} map[string]interface{}
+// @Router /predict/{id}/events [get]
+func (c *PredictController) JobEvents(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	job, err := c.jobRepo.Get(ctx.Param("id"), sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error reading prediction job", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading prediction job"})
+		return
+	}
+
+	lastID, _ := strconv.Atoi(ctx.GetHeader("Last-Event-ID"))
+	history, events, known, cancel := c.progress.Subscribe(job.ID)
+	defer cancel()
+
+	ctx.Header("Cache-Control", "no-cache")
+	// Keep reverse proxies from buffering the stream
+	ctx.Header("X-Accel-Buffering", "no")
+
+	if !known {
+		// Nothing was published here, e.g. the job finished before a restart,
+		// so report its stored outcome if there is one
+		if final := jobFinalEvent(job); final != nil {
+			sendProgress(ctx, *final)
+			return
+		}
+	}
+	for _, event := range history {
+		if event.ID > lastID {
+			sendProgress(ctx, event)
+		}
+		if event.Final() {
+			return
+		}
+	}
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			sendProgress(ctx, event)
+			return !event.Final()
+		case <-ticker.C:
+			current, err := c.jobRepo.Get(job.ID, sub.(string))
+			if err == nil {
+				if final := jobFinalEvent(current); final != nil {
+					sendProgress(ctx, *final)
+					return false
+				}
+			}
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// jobFinalEvent returns the final event of a finished job from its stored
+// outcome, or nil while it is still queued or running.
+func jobFinalEvent(job *entity.PredictionJob) *entity.ProgressEvent {
+	switch job.Status {
+	case entity.PredictionJobSucceeded:
+		prediction := &entity.Prediction{ID: job.PredictionID, Model: job.MLBackend, Result: job.Result, Timeline: job.Timeline, Sentence: job.Sentence}
+		return &entity.ProgressEvent{Stage: entity.StageDone, At: job.UpdatedAt, Data: predictionResponse(prediction)}
+	case entity.PredictionJobFailed:
+		return &entity.ProgressEvent{Stage: entity.StageError, At: job.UpdatedAt, Error: job.Error}
+	}
+	return nil
+}
+
+func sendProgress(ctx *gin.Context, event entity.ProgressEvent) {
+	var id string
+	if event.ID > 0 {
+		id = strconv.Itoa(event.ID)
+	}
+	ctx.Render(-1, sse.Event{Id: id, Event: string(event.Stage), Data: event})
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// Error codes sent on a live prediction stream.
+const (
+	codeStreamInvalidMessage = "stream_invalid_message"
+	codeStreamIdle           = "stream_idle"
+	codeStreamTooLong        = "stream_too_long"
+	codeStreamInference      = "stream_inference_failed"
+)
+
+// streamQueueSize is how many windows may wait for inference. When the queue
+// is full new frame windows are dropped, so a slow ML service never builds up
+// latency.
+const streamQueueSize = 2
+
+// streamWriteTimeout bounds how long a message to the client may take.
+const streamWriteTimeout = 10 * time.Second
+
+// StreamLimits configure how a live stream is cut into windows and how long
+// and how fast it may run.
+type StreamLimits struct {
+	// WindowFrames is the number of frames per window in frames mode
+	WindowFrames int
+	// WindowBytes is the minimum size of a window in chunks mode
+	WindowBytes     int
+	MaxMessageBytes int
+	IdleTimeout     time.Duration
+	MaxDuration     time.Duration
+	FrameTypes      []string
+	ChunkTypes      []string
+}
+
+// streamFrame is a WebSocket message together with its frame type.
+type streamFrame struct {
+	data   []byte
+	binary bool
+}
+
+// streamCodec receives messages without losing whether they were text or binary.
+var streamCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		frame := v.(*streamFrame)
+		frame.data = data
+		frame.binary = payloadType == websocket.BinaryFrame
+		return nil
+	},
+}
+
+// @Summary Stream live predictions
+// @Description Upgrades to a WebSocket. Send frames as binary messages, either one encoded image each (mode=frames) or consecutive chunks of a video recording (mode=chunks).
+// @Description Frames are grouped into windows and sent to the ML service as they fill up. Whenever the best class of a window changes, a recognized message with the ranked classes and their translations is sent back.
+// @Description Send the text message {"type":"flush"} to infer a partial window right away, and {"type":"end"} to flush and close once all results were sent.
+// @Description Browsers, which cannot set the Authorization header, may offer the subprotocols "bearer" and the access token instead.
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string false "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   Sec-WebSocket-Protocol header string false "bearer, {token}"
+// @Param   mode query string false "frames or chunks" Enums(frames, chunks) default(frames)
+// @Param   content_type query string false "Content type of the binary messages. Defaults to image/jpeg for frames and video/webm for chunks"
+// @Param   language query string false "Target languages, comma separated. Defaults to the preferred Accept-Language, then the user's default language"
+// @Param   Accept-Language header string false "Preferred target language when no language is given"
+// @Param   top_k query int false "Maximum number of classes to return per window, 0 for all"
+// @Param   min_average query number false "Minimum average confidence of a returned class"
+// @Param   min_count query int false "Minimum number of frames a returned class was predicted in"
+// @Success  101 {object} entity.StreamMessage
+// @Failure  400 {object} map[string]interface{}
+// @Failure  415 {object} map[string]interface{}
+// @Router /stream [get]
+func (c *PredictController) Stream(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	options, ok := c.requestOptions(ctx, sub.(string))
+	if !ok {
+		return
+	}
+
+	mode := entity.StreamMode(ctx.DefaultQuery("mode", string(entity.StreamFrames)))
+	var allowedTypes []string
+	switch mode {
+	case entity.StreamFrames:
+		allowedTypes = c.streamLimits.FrameTypes
+	case entity.StreamChunks:
+		allowedTypes = c.streamLimits.ChunkTypes
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "mode must be frames or chunks"})
+		return
+	}
+	contentType := ctx.Query("content_type")
+	if contentType == "" && len(allowedTypes) > 0 {
+		contentType = allowedTypes[0]
+	}
+	if !containsString(allowedTypes, contentType) {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": fmt.Sprintf("Content type %s is not supported in %s mode", contentType, mode), "code": validators.CodeVideoUnsupported})
+		return
+	}
+
+	session, err := database.NewID()
+	if err != nil {
+		c.logger.Error("Error generating stream session ID", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while starting the stream"})
+		return
+	}
+
+	server := websocket.Server{
+		// Authentication already happened, and it relies on a token rather
+		// than cookies, so the origin of the page does not matter
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			protocols := config.Protocol
+			config.Protocol = nil
+			if containsString(protocols, middleware.WebSocketTokenProtocol) {
+				// Browsers drop the connection unless one of the offered
+				// subprotocols is selected
+				config.Protocol = []string{middleware.WebSocketTokenProtocol}
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			c.serveStream(ws, sub.(string), session, mode, contentType, options)
+		},
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// serveStream reads the client's messages, cuts them into windows and hands
+// those to the inference loop until the client ends the stream, goes idle or
+// runs out of time.
+func (c *PredictController) serveStream(ws *websocket.Conn, sub string, session string, mode entity.StreamMode, contentType string, options *predictOptions) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = c.streamLimits.MaxMessageBytes
+	logger := c.logger.With(zap.String("sub", sub), zap.String("session", session))
+	logger.Info("Stream started", zap.String("mode", string(mode)), zap.String("content_type", contentType))
+
+	var sendMu sync.Mutex
+	send := func(message entity.StreamMessage) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := websocket.JSON.Send(ws, message); err != nil {
+			logger.Debug("Failed to send stream message", zap.Error(err))
+		}
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	windows := make(chan httpadapter.StreamWindow, streamQueueSize)
+	inferred := make(chan struct{})
+	go func() {
+		defer close(inferred)
+		c.inferStream(streamCtx, windows, options, send, logger)
+	}()
+
+	send(entity.StreamMessage{Type: entity.StreamReady, Session: session})
+
+	var (
+		parts    [][]byte
+		size     int
+		seq      int
+		graceful bool
+	)
+	enqueue := func() {
+		if len(parts) == 0 {
+			return
+		}
+		seq++
+		window := httpadapter.StreamWindow{Session: session, Seq: seq, ContentType: contentType, Parts: parts}
+		parts, size = nil, 0
+		if mode == entity.StreamChunks {
+			// A container cannot be decoded with chunks missing, so the
+			// client has to wait instead
+			windows <- window
+			return
+		}
+		select {
+		case windows <- window:
+		default:
+			send(entity.StreamMessage{Type: entity.StreamDropped, Window: seq})
+		}
+	}
+
+	deadline := time.Now().Add(c.streamLimits.MaxDuration)
+	for {
+		readDeadline := time.Now().Add(c.streamLimits.IdleTimeout)
+		if c.streamLimits.MaxDuration > 0 && readDeadline.After(deadline) {
+			readDeadline = deadline
+		}
+		ws.SetReadDeadline(readDeadline)
+
+		var frame streamFrame
+		err := streamCodec.Receive(ws, &frame)
+		if errors.Is(err, websocket.ErrFrameTooLarge) {
+			send(entity.StreamMessage{Type: entity.StreamError, Error: fmt.Sprintf("Messages must not be larger than %d bytes", c.streamLimits.MaxMessageBytes), Code: validators.CodeVideoTooLarge})
+			continue
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			if !time.Now().Before(deadline) {
+				send(entity.StreamMessage{Type: entity.StreamError, Error: fmt.Sprintf("Streams must not be longer than %s", c.streamLimits.MaxDuration), Code: codeStreamTooLong})
+			} else {
+				send(entity.StreamMessage{Type: entity.StreamError, Error: fmt.Sprintf("No data received for %s", c.streamLimits.IdleTimeout), Code: codeStreamIdle})
+			}
+			break
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				logger.Debug("Stream read failed", zap.Error(err))
+			}
+			break
+		}
+
+		if frame.binary {
+			parts = append(parts, frame.data)
+			size += len(frame.data)
+			if (mode == entity.StreamFrames && len(parts) >= c.streamLimits.WindowFrames) || (mode == entity.StreamChunks && size >= c.streamLimits.WindowBytes) {
+				enqueue()
+			}
+			continue
+		}
+
+		var message entity.StreamMessage
+		if err := json.Unmarshal(frame.data, &message); err != nil {
+			send(entity.StreamMessage{Type: entity.StreamError, Error: "Text messages must be JSON", Code: codeStreamInvalidMessage})
+			continue
+		}
+		switch message.Type {
+		case entity.StreamFlush:
+			enqueue()
+			continue
+		case entity.StreamEnd:
+			enqueue()
+			graceful = true
+		default:
+			send(entity.StreamMessage{Type: entity.StreamError, Error: fmt.Sprintf("Unknown message type %q", message.Type), Code: codeStreamInvalidMessage})
+			continue
+		}
+		break
+	}
+
+	if !graceful {
+		// Nobody is waiting for the results of the remaining windows
+		cancel()
+	}
+	close(windows)
+	<-inferred
+	if graceful {
+		send(entity.StreamMessage{Type: entity.StreamClosed, Session: session})
+	}
+	logger.Info("Stream ended", zap.Int("windows", seq))
+}
+
+// inferStream runs inference on each window in turn and reports the ranked
+// classes whenever the best class changes. Translations are looked up once
+// per class and language for the whole stream.
+func (c *PredictController) inferStream(ctx context.Context, windows <-chan httpadapter.StreamWindow, options *predictOptions, send func(entity.StreamMessage), logger *zap.Logger) {
+	overrides, err := c.thresholdRepo.ByClass()
+	if err != nil {
+		logger.Warn("Failed to read class thresholds", zap.Error(err))
+	}
+	translations := make(map[string]map[string]string)
+	var last string
+
+	for window := range windows {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		avg, err := c.inferWindow(ctx, window, options, overrides)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Stream inference failed", zap.Int("window", window.Seq), zap.Error(err))
+				send(entity.StreamMessage{Type: entity.StreamError, Window: window.Seq, Error: "Error while predicting the window", Code: codeStreamInference})
+			}
+			continue
+		}
+		if len(avg) == 0 || avg[0].Key == last {
+			continue
+		}
+
+		classes, err := c.streamClasses(avg, options.Languages, translations)
+		if err != nil {
+			logger.Error("Error translating stream classes", zap.Int("window", window.Seq), zap.Error(err))
+			send(entity.StreamMessage{Type: entity.StreamError, Window: window.Seq, Error: "Error translating data", Code: codeStreamInference})
+			continue
+		}
+		last = avg[0].Key
+		send(entity.StreamMessage{Type: entity.StreamRecognized, Window: window.Seq, Classes: classes})
+	}
+}
+
+// inferWindow returns the ranked classes the ML service recognised in a window.
+func (c *PredictController) inferWindow(ctx context.Context, window httpadapter.StreamWindow, options *predictOptions, overrides map[string]entity.ClassThreshold) ([]entity.ProcessedAvg, error) {
+	infer, err := c.mlStream.PredictWindow(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	_, avg, err := c.processMLResult(infer)
+	if err != nil {
+		return nil, err
+	}
+	return rankClasses(avg, options.Result, overrides), nil
+}
+
+// streamClasses translates the ranked classes of a window, reusing the
+// translations in cache and adding new ones to it.
+func (c *PredictController) streamClasses(avg []entity.ProcessedAvg, languages []string, cache map[string]map[string]string) ([]entity.PredictResponse, error) {
+	classes := make([]entity.PredictResponse, len(avg))
+	for i, a := range avg {
+		translations, ok := cache[a.Key]
+		if !ok {
+			translations = make(map[string]string, len(languages))
+			for _, language := range languages {
+				translatedData, err := c.translateData(a.Key, language)
+				if err != nil {
+					return nil, err
+				}
+				translations[language] = *translatedData
+			}
+			cache[a.Key] = translations
+		}
+		classes[i] = entity.PredictResponse{
+			Class:        a.Key,
+			Translations: translations,
+			Average:      a.Average,
+			Sum:          a.Sum,
+			Count:        a.Count,
+		}
+	}
+	return classes, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// stubClasses are the signs the stub ML server recognises, one per window in
+// turn, so every window changes the best class.
+var stubClasses = []string{"hello", "thank_you", "please", "yes", "no"}
+
+// stubWindow is a window as received by the stub ML server.
+type stubWindow struct {
+	Session      string
+	Seq          int
+	Parts        int
+	ContentTypes []string
+}
+
+// streamStub is a local stand-in for the ML service's /stream/predict.
+type streamStub struct {
+	windows chan stubWindow
+	// release, if set, holds every response until it is closed
+	release chan struct{}
+}
+
+func newStreamStub() *streamStub {
+	return &streamStub{windows: make(chan stubWindow, 32)}
+}
+
+func (s *streamStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/stream/predict" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seq, err := strconv.Atoi(r.FormValue("seq"))
+	if err != nil {
+		http.Error(w, "seq is required", http.StatusBadRequest)
+		return
+	}
+	window := stubWindow{Session: r.FormValue("session"), Seq: seq}
+	for _, part := range r.MultipartForm.File["data"] {
+		window.Parts++
+		window.ContentTypes = append(window.ContentTypes, part.Header.Get("Content-Type"))
+	}
+	s.windows <- window
+
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	class := stubClasses[seq%len(stubClasses)]
+	var response valueobjects.MlResponse
+	response.Results.Avg = map[string]valueobjects.MlAverage{class: {Average: 0.9, Sum: 0.9 * float64(window.Parts), Count: window.Parts}}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// next returns the next window the stub received.
+func (s *streamStub) next(t *testing.T) stubWindow {
+	t.Helper()
+	select {
+	case window := <-s.windows:
+		return window
+	case <-time.After(5 * time.Second):
+		t.Fatal("no window reached the ML service")
+		return stubWindow{}
+	}
+}
+
+// failingDB fails every query, like a database that is down.
+type failingDB struct{}
+
+var errDatabaseDown = errors.New("database is down")
+
+func (failingDB) Query(string, ...interface{}) (*sql.Rows, error) { return nil, errDatabaseDown }
+func (failingDB) QueryRow(string, ...interface{}) *sql.Row        { return nil }
+func (failingDB) Exec(string, ...interface{}) (sql.Result, error) { return nil, errDatabaseDown }
+
+func testStreamLimits() StreamLimits {
+	return StreamLimits{
+		WindowFrames:    3,
+		WindowBytes:     10,
+		MaxMessageBytes: 1 << 10,
+		IdleTimeout:     5 * time.Second,
+		MaxDuration:     time.Minute,
+		FrameTypes:      []string{"image/jpeg"},
+		ChunkTypes:      []string{"video/webm"},
+	}
+}
+
+// startStream opens a stream against a controller that sends windows to ml,
+// reads the ready message and returns the connection.
+func startStream(t *testing.T, limits StreamLimits, ml http.Handler, query string) *websocket.Conn {
+	t.Helper()
+	mlServer := httptest.NewServer(ml)
+	t.Cleanup(mlServer.Close)
+
+	c := &PredictController{
+		logger:          zap.NewNop(),
+		languages:       validators.Languages{Supported: []string{"en", "th"}, Max: 3},
+		defaultLanguage: "en",
+		thresholdRepo:   database.NewClassThresholdRepository(failingDB{}),
+		mlStream:        httpadapter.NewMLStreamService(mlServer.URL, httpadapter.MLClientOptions{RequestTimeout: 10 * time.Second}),
+		streamLimits:    limits,
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", func(ctx *gin.Context) { ctx.Set("sub", "user") }, c.Stream)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/stream?language=en&"+query, "", server.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	if ready := receive(t, ws); ready.Type != entity.StreamReady || ready.Session == "" {
+		t.Fatalf("first message = %+v, want ready with a session", ready)
+	}
+	return ws
+}
+
+func receive(t *testing.T, ws *websocket.Conn) entity.StreamMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message entity.StreamMessage
+	if err := websocket.JSON.Receive(ws, &message); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return message
+}
+
+func sendFrames(t *testing.T, ws *websocket.Conn, n int, size int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := websocket.Message.Send(ws, make([]byte, size)); err != nil {
+			t.Fatalf("send frame: %v", err)
+		}
+	}
+}
+
+func sendControl(t *testing.T, ws *websocket.Conn, messageType entity.StreamMessageType) {
+	t.Helper()
+	if err := websocket.JSON.Send(ws, entity.StreamMessage{Type: messageType}); err != nil {
+		t.Fatalf("send %s: %v", messageType, err)
+	}
+}
+
+// receiveUntilClosed collects the messages up to and including closed.
+func receiveUntilClosed(t *testing.T, ws *websocket.Conn) []entity.StreamMessage {
+	t.Helper()
+	var messages []entity.StreamMessage
+	for {
+		message := receive(t, ws)
+		messages = append(messages, message)
+		if message.Type == entity.StreamClosed {
+			return messages
+		}
+	}
+}
+
+func recognizedWindows(messages []entity.StreamMessage) []int {
+	var windows []int
+	for _, message := range messages {
+		if message.Type == entity.StreamRecognized {
+			windows = append(windows, message.Window)
+		}
+	}
+	return windows
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStreamCutsFramesIntoWindows(t *testing.T) {
+	stub := newStreamStub()
+	ws := startStream(t, testStreamLimits(), stub, "")
+
+	// Two full windows of three frames and one left over for end to flush
+	sendFrames(t, ws, 7, 8)
+	sendControl(t, ws, entity.StreamEnd)
+
+	var session string
+	for i, want := range []int{3, 3, 1} {
+		window := stub.next(t)
+		if window.Seq != i+1 || window.Parts != want {
+			t.Errorf("window %d: seq %d with %d parts, want seq %d with %d", i, window.Seq, window.Parts, i+1, want)
+		}
+		for _, contentType := range window.ContentTypes {
+			if contentType != "image/jpeg" {
+				t.Errorf("window %d: part content type %q, want image/jpeg", window.Seq, contentType)
+			}
+		}
+		if session == "" {
+			session = window.Session
+		} else if window.Session != session {
+			t.Errorf("window %d: session %q, want %q", window.Seq, window.Session, session)
+		}
+	}
+
+	messages := receiveUntilClosed(t, ws)
+	if got := recognizedWindows(messages); !equalInts(got, []int{1, 2, 3}) {
+		t.Errorf("recognized windows %v, want [1 2 3]", got)
+	}
+	last := messages[len(messages)-2]
+	if len(last.Classes) != 1 || last.Classes[0].Class != stubClasses[3] || last.Classes[0].Translations["en"] != stubClasses[3] {
+		t.Errorf("window 3 classes = %+v, want %s", last.Classes, stubClasses[3])
+	}
+}
+
+func TestStreamCutsChunksIntoWindowsBySize(t *testing.T) {
+	stub := newStreamStub()
+	ws := startStream(t, testStreamLimits(), stub, "mode=chunks")
+
+	// 4 + 4 + 4 bytes reach the 10 byte window
+	sendFrames(t, ws, 3, 4)
+	window := stub.next(t)
+	if window.Seq != 1 || window.Parts != 3 {
+		t.Errorf("window: seq %d with %d parts, want seq 1 with 3", window.Seq, window.Parts)
+	}
+	if window.ContentTypes[0] != "video/webm" {
+		t.Errorf("part content type %q, want video/webm", window.ContentTypes[0])
+	}
+
+	sendControl(t, ws, entity.StreamEnd)
+	receiveUntilClosed(t, ws)
+}
+
+func TestStreamFlushSendsPartialWindow(t *testing.T) {
+	stub := newStreamStub()
+	ws := startStream(t, testStreamLimits(), stub, "")
+
+	sendFrames(t, ws, 2, 8)
+	sendControl(t, ws, entity.StreamFlush)
+	if window := stub.next(t); window.Seq != 1 || window.Parts != 2 {
+		t.Errorf("flushed window: seq %d with %d parts, want seq 1 with 2", window.Seq, window.Parts)
+	}
+	if message := receive(t, ws); message.Type != entity.StreamRecognized || message.Window != 1 {
+		t.Errorf("after flush got %+v, want recognized window 1", message)
+	}
+
+	// Nothing is pending, so end only closes the stream
+	sendControl(t, ws, entity.StreamEnd)
+	if message := receive(t, ws); message.Type != entity.StreamClosed {
+		t.Errorf("after end got %+v, want closed", message)
+	}
+	select {
+	case window := <-stub.windows:
+		t.Errorf("empty window %d sent to the ML service", window.Seq)
+	default:
+	}
+}
+
+func TestStreamDropsFramesWhenQueueIsFull(t *testing.T) {
+	stub := newStreamStub()
+	stub.release = make(chan struct{})
+	limits := testStreamLimits()
+	limits.WindowFrames = 1
+	ws := startStream(t, limits, stub, "")
+
+	// Window 1 holds up inference, so windows 2 and 3 fill the queue and
+	// window 4 has no room
+	sendFrames(t, ws, 1, 8)
+	stub.next(t)
+	sendFrames(t, ws, streamQueueSize+1, 8)
+	if message := receive(t, ws); message.Type != entity.StreamDropped || message.Window != streamQueueSize+2 {
+		t.Fatalf("got %+v, want window %d dropped", message, streamQueueSize+2)
+	}
+
+	close(stub.release)
+	sendControl(t, ws, entity.StreamEnd)
+	messages := receiveUntilClosed(t, ws)
+	if got := recognizedWindows(messages); !equalInts(got, []int{1, 2, 3}) {
+		t.Errorf("recognized windows %v, want [1 2 3]", got)
+	}
+}
+
+func TestStreamRejectsUnknownMessages(t *testing.T) {
+	ws := startStream(t, testStreamLimits(), newStreamStub(), "")
+
+	if err := websocket.Message.Send(ws, "not json"); err != nil {
+		t.Fatal(err)
+	}
+	if message := receive(t, ws); message.Type != entity.StreamError || message.Code != codeStreamInvalidMessage {
+		t.Errorf("got %+v, want %s error", message, codeStreamInvalidMessage)
+	}
+	sendControl(t, ws, "pause")
+	if message := receive(t, ws); message.Type != entity.StreamError || message.Code != codeStreamInvalidMessage {
+		t.Errorf("got %+v, want %s error", message, codeStreamInvalidMessage)
+	}
+}
+
+func TestStreamTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		idleTimeout time.Duration
+		maxDuration time.Duration
+		code        string
+	}{
+		{name: "idle", idleTimeout: 100 * time.Millisecond, maxDuration: time.Minute, code: codeStreamIdle},
+		{name: "too long", idleTimeout: time.Minute, maxDuration: 200 * time.Millisecond, code: codeStreamTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := testStreamLimits()
+			limits.IdleTimeout = tt.idleTimeout
+			limits.MaxDuration = tt.maxDuration
+			ws := startStream(t, limits, newStreamStub(), "")
+
+			if message := receive(t, ws); message.Type != entity.StreamError || message.Code != tt.code {
+				t.Errorf("got %+v, want %s error", message, tt.code)
+			}
+			var message entity.StreamMessage
+			if err := websocket.JSON.Receive(ws, &message); !errors.Is(err, io.EOF) {
+				t.Errorf("after the timeout got %+v, %v, want the stream closed", message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// videoURLExpiry is how long a presigned link to a stored video stays valid.
+	videoURLExpiry = 15 * time.Minute
+)
+
+type PredictionController struct {
+	logger         *zap.Logger
+	s3Adapter      s3.S3Adapter
+	predictionRepo *database.PredictionRepository
+	jobRepo        *database.PredictionJobRepository
+	cacheRepo      *database.PredictionCacheRepository
+	linkRepo       *database.S3LinkRepository
+	auditRepo      *database.AuditRepository
+	deliveryRepo   *database.WebhookDeliveryRepository
+	idemRepo       *database.IdempotencyRepository
+}
+
+func NewPredictionController(s3Adapter s3.S3Adapter, predictionRepo *database.PredictionRepository, jobRepo *database.PredictionJobRepository, cacheRepo *database.PredictionCacheRepository, linkRepo *database.S3LinkRepository, auditRepo *database.AuditRepository, deliveryRepo *database.WebhookDeliveryRepository, idemRepo *database.IdempotencyRepository, logger *zap.Logger) *PredictionController {
+	return &PredictionController{
+		logger:         logger,
+		s3Adapter:      s3Adapter,
+		predictionRepo: predictionRepo,
+		jobRepo:        jobRepo,
+		cacheRepo:      cacheRepo,
+		linkRepo:       linkRepo,
+		auditRepo:      auditRepo,
+		deliveryRepo:   deliveryRepo,
+		idemRepo:       idemRepo,
+	}
+}
+
+// @Summary List my predictions
+// @Description Lists the caller's past predictions, newest first. Pass next_cursor from the previous page as cursor to get the next one.
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   cursor query string false "Cursor from the previous page"
+// @Param   limit query int false "Page size (default 20, max 100)"
+// @Param   from query string false "Only predictions created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param   to query string false "Only predictions created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param   class query []string false "Only predictions whose result shows any of these classes" collectionFormat(multi)
+// @Success  200 {object} map[string]interface{}
+// @Failure  400 {object} map[string]interface{}
+// @Router /predictions [get]
+func (c *PredictionController) ListPredictions(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	filter := entity.PredictionFilter{Sub: sub.(string), Limit: defaultPageSize, Classes: ctx.QueryArray("class")}
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		filter.Before = before
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageSize {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(ctx, "from"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseTimeQuery(ctx, "to"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+	predictions, err := c.predictionRepo.List(filter)
+	if err != nil {
+		c.logger.Error("Error listing predictions", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing predictions"})
+		return
+	}
+
+	var nextCursor string
+	if len(predictions) > pageSize {
+		predictions = predictions[:pageSize]
+		nextCursor = strconv.FormatInt(predictions[pageSize-1].ID, 10)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": predictions, "next_cursor": nextCursor})
+}
+
+// @Summary Get a prediction
+// @Description Returns a stored prediction with its full result and a short-lived link to the original video
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path int true "Prediction ID"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.PredictionDetail}
+// @Failure  404 {object} map[string]interface{}
+// @Router /predictions/{id} [get]
+func (c *PredictionController) GetPrediction(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+
+	prediction, err := c.predictionRepo.Get(id, sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error reading prediction", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading prediction"})
+		return
+	}
+
+	if prediction.Timeline == nil && len(prediction.Raw) > 0 {
+		// Recorded before timelines were stored
+		prediction.Timeline = buildTimeline(prediction.Raw, 0)
+	}
+
+	url, err := c.s3Adapter.PresignGetObject(prediction.VideoKey, videoURLExpiry)
+	if err != nil {
+		c.logger.Error("Error presigning video URL", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading prediction"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": entity.PredictionDetail{
+		Prediction:        *prediction,
+		VideoURL:          url,
+		VideoURLExpiresAt: time.Now().UTC().Add(videoURLExpiry),
+	}})
+}
+
+// @Summary Delete a prediction
+// @Description Deletes a prediction and the webhook deliveries that carried it. The uploaded video and its cached results are deleted too unless another prediction of the caller still uses them.
+// @Tags api
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path int true "Prediction ID"
+// @Success  204
+// @Failure  404 {object} map[string]interface{}
+// @Router /predictions/{id} [delete]
+func (c *PredictionController) DeletePrediction(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+
+	prediction, err := c.predictionRepo.Get(id, sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prediction not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error reading prediction", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+		return
+	}
+
+	references, err := c.predictionRepo.CountByVideoKey(sub.(string), prediction.VideoKey)
+	if err != nil {
+		c.logger.Error("Error counting video references", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+		return
+	}
+
+	// Objects are removed before records, so a failed request can be retried
+	videoDeleted := references <= 1
+	if videoDeleted {
+		if err := c.deleteVideo(ctx, sub.(string), prediction); err != nil {
+			c.logger.Error("Error deleting video", zap.String("key", prediction.VideoKey), zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+			return
+		}
+	}
+
+	if err := c.jobRepo.DeleteByPrediction(id); err != nil {
+		c.logger.Error("Error deleting prediction jobs", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+		return
+	}
+	if err := c.deliveryRepo.DeleteByPrediction(sub.(string), id); err != nil {
+		c.logger.Error("Error deleting webhook deliveries", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+		return
+	}
+	if err := c.predictionRepo.Delete(id, sub.(string)); err != nil {
+		c.logger.Error("Error deleting prediction", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting prediction"})
+		return
+	}
+
+	detail := gin.H{"video_key": prediction.VideoKey, "video_deleted": videoDeleted}
+	if err := c.auditRepo.Record(sub.(string), database.AuditDeletePrediction, strconv.FormatInt(id, 10), detail); err != nil {
+		c.logger.Error("Failed to write audit entry", zap.String("action", database.AuditDeletePrediction), zap.Error(err))
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary Delete all my videos
+// @Description Deletes every uploaded video of the caller together with all predictions, jobs, cached results and the webhook deliveries that carried them
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Success  200 {object} map[string]interface{}
+// @Router /me/videos [delete]
+func (c *PredictionController) DeleteAllVideos(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	keys, contentHashes, err := c.predictionRepo.Videos(sub.(string))
+	if err != nil {
+		c.logger.Error("Error listing videos", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+
+	// Links recorded before predictions were stored may point outside the
+	// per-user prefixes
+	links, err := c.linkRepo.Links(sub.(string))
+	if err != nil {
+		c.logger.Error("Error listing video links", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	for _, link := range links {
+		if key, ok := c.s3Adapter.KeyFromURI(link); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	reqCtx := ctx.Request.Context()
+	if err := c.s3Adapter.DeleteObjects(reqCtx, keys); err != nil {
+		c.logger.Error("Error deleting videos", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	for _, prefix := range []string{videoPrefix(sub.(string)), uploadPrefix(sub.(string))} {
+		if err := c.s3Adapter.DeletePrefix(reqCtx, prefix); err != nil {
+			c.logger.Error("Error deleting videos", zap.String("prefix", prefix), zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+			return
+		}
+	}
+
+	if err := c.cacheRepo.Delete(contentHashes...); err != nil {
+		c.logger.Error("Error deleting cached results", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	if err := c.jobRepo.DeleteBySub(sub.(string)); err != nil {
+		c.logger.Error("Error deleting prediction jobs", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	if err := c.deliveryRepo.DeleteBySub(sub.(string)); err != nil {
+		c.logger.Error("Error deleting webhook deliveries", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	// Stored responses of retried requests hold prediction results too
+	if err := c.idemRepo.DeleteBySub(sub.(string)); err != nil {
+		c.logger.Error("Error deleting idempotency keys", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	deleted, err := c.predictionRepo.DeleteBySub(sub.(string))
+	if err != nil {
+		c.logger.Error("Error deleting predictions", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+	if err := c.linkRepo.Delete(sub.(string)); err != nil {
+		c.logger.Error("Error deleting video links", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting videos"})
+		return
+	}
+
+	detail := gin.H{"videos": len(keys), "predictions": deleted}
+	if err := c.auditRepo.Record(sub.(string), database.AuditDeleteAllVideos, "", detail); err != nil {
+		c.logger.Error("Failed to write audit entry", zap.String("action", database.AuditDeleteAllVideos), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"deleted_predictions": deleted}})
+}
+
+// deleteVideo removes the stored video of a prediction, its link and any
+// cached results for its content.
+func (c *PredictionController) deleteVideo(ctx *gin.Context, sub string, prediction *entity.Prediction) error {
+	if err := c.s3Adapter.DeleteObjects(ctx.Request.Context(), []string{prediction.VideoKey}); err != nil {
+		return err
+	}
+	if err := c.linkRepo.RemoveLink(sub, c.s3Adapter.URI(prediction.VideoKey)); err != nil {
+		return err
+	}
+	if prediction.ContentHash != "" {
+		return c.cacheRepo.Delete(prediction.ContentHash)
+	}
+	return nil
+}
+
+// parseTimeQuery parses an optional RFC 3339 or YYYY-MM-DD query parameter.
+func parseTimeQuery(ctx *gin.Context, name string) (*time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := validators.ParseTime(value)
+	if err != nil {
+		return nil, errors.New(name + " " + err.Error())
+	}
+	return &t, nil
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// ErrQueueFull is returned by Enqueue when no more jobs can be buffered.
+var ErrQueueFull = errors.New("prediction queue is full")
+
+const (
+	// staleJobAge is how long a job may stay running before it is assumed to
+	// be abandoned by a lost worker and requeued.
+	staleJobAge = 15 * time.Minute
+	// staleJobSweepInterval is how often stale jobs are looked for while the
+	// pool is running.
+	staleJobSweepInterval = time.Minute
+)
+
+// PredictionWorkerPool runs queued prediction jobs in the background.
+type PredictionWorkerPool struct {
+	logger  *zap.Logger
+	jobRepo *database.PredictionJobRepository
+	queue   chan string
+	workers int
+}
+
+func NewPredictionWorkerPool(jobRepo *database.PredictionJobRepository, workers int, queueSize int, logger *zap.Logger) *PredictionWorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &PredictionWorkerPool{
+		logger:  logger,
+		jobRepo: jobRepo,
+		queue:   make(chan string, queueSize),
+		workers: workers,
+	}
+}
+
+// Enqueue schedules a job that is already stored as queued.
+func (p *PredictionWorkerPool) Enqueue(id string) error {
+	select {
+	case p.queue <- id:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start launches the workers, reschedules jobs left over from a previous run
+// and keeps requeueing jobs abandoned by lost workers. Workers stop once ctx
+// is cancelled, which is also passed to handle so running jobs can be
+// interrupted.
+func (p *PredictionWorkerPool) Start(ctx context.Context, handle func(ctx context.Context, job *entity.PredictionJob)) {
+	for i := 0; i < p.workers; i++ {
+		go p.work(ctx, handle)
+	}
+	go p.resume(ctx)
+}
+
+func (p *PredictionWorkerPool) work(ctx context.Context, handle func(ctx context.Context, job *entity.PredictionJob)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			job, claimed, err := p.jobRepo.Claim(id)
+			if err != nil {
+				p.logger.Error("Failed to claim prediction job", zap.String("job", id), zap.Error(err))
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			handle(ctx, job)
+		}
+	}
+}
+
+func (p *PredictionWorkerPool) resume(ctx context.Context) {
+	if _, err := p.jobRepo.RequeueStale(staleJobAge); err != nil {
+		p.logger.Error("Failed to requeue stale prediction jobs", zap.Error(err))
+	}
+
+	ids, err := p.jobRepo.ListQueuedIDs()
+	if err != nil {
+		p.logger.Error("Failed to list queued prediction jobs", zap.Error(err))
+	} else {
+		p.schedule(ctx, ids)
+		if len(ids) > 0 {
+			p.logger.Info("Resumed queued prediction jobs", zap.Int("count", len(ids)))
+		}
+	}
+
+	ticker := time.NewTicker(staleJobSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ids, err := p.jobRepo.RequeueStale(staleJobAge)
+		if err != nil {
+			p.logger.Error("Failed to requeue stale prediction jobs", zap.Error(err))
+		}
+		p.schedule(ctx, ids)
+		if len(ids) > 0 {
+			p.logger.Warn("Requeued abandoned prediction jobs", zap.Int("count", len(ids)))
+		}
+	}
+}
+
+// schedule hands ids to the workers, waiting for room in the queue.
+func (p *PredictionWorkerPool) schedule(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return
+		case p.queue <- id:
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// progressRetention is how long the events of a finished prediction are kept
+// for clients that subscribe late.
+const progressRetention = 5 * time.Minute
+
+// progressBuffer is how many events a subscriber may fall behind by before
+// it is cut off.
+const progressBuffer = 8
+
+// ProgressBroker fans the progress events of prediction jobs out to
+// subscribers. Events are kept in memory so late subscribers get a replay.
+type ProgressBroker struct {
+	mu      sync.Mutex
+	streams map[string]*progressStream
+}
+
+type progressStream struct {
+	events      []entity.ProgressEvent
+	subscribers map[chan entity.ProgressEvent]struct{}
+	done        bool
+}
+
+func NewProgressBroker() *ProgressBroker {
+	return &ProgressBroker{streams: make(map[string]*progressStream)}
+}
+
+// Publish records that job id reached stage. stageDuration overrides the
+// time spent in the previous stage when it is known better by the caller.
+func (b *ProgressBroker) Publish(id string, stage entity.PredictionStage, stageDuration time.Duration, data interface{}, message string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, ok := b.streams[id]
+	if !ok {
+		stream = &progressStream{subscribers: make(map[chan entity.ProgressEvent]struct{})}
+		b.streams[id] = stream
+	}
+	if stream.done {
+		return
+	}
+
+	now := time.Now().UTC()
+	event := entity.ProgressEvent{ID: len(stream.events) + 1, Stage: stage, At: now, Data: data, Error: message}
+	if len(stream.events) > 0 {
+		first, last := stream.events[0], stream.events[len(stream.events)-1]
+		event.ElapsedMs = now.Sub(first.At).Milliseconds()
+		event.StageMs = now.Sub(last.At).Milliseconds()
+	}
+	if stageDuration > 0 {
+		event.StageMs = stageDuration.Milliseconds()
+	}
+	stream.events = append(stream.events, event)
+
+	for ch := range stream.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// A subscriber that fell behind is cut off rather than holding up
+			// every job. Its stream ends and the client reconnects with
+			// Last-Event-ID to get the events it missed from the history.
+			delete(stream.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	if event.Final() {
+		stream.done = true
+		for ch := range stream.subscribers {
+			close(ch)
+		}
+		stream.subscribers = nil
+		time.AfterFunc(progressRetention, func() {
+			b.mu.Lock()
+			delete(b.streams, id)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Subscribe returns the events published so far for job id and a channel of
+// the ones that follow, which is closed after the final event. known is false
+// when no event was ever published for the job by this process.
+func (b *ProgressBroker) Subscribe(id string) (history []entity.ProgressEvent, events <-chan entity.ProgressEvent, known bool, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan entity.ProgressEvent, progressBuffer)
+	stream, ok := b.streams[id]
+	if !ok {
+		stream = &progressStream{subscribers: make(map[chan entity.ProgressEvent]struct{})}
+		b.streams[id] = stream
+	}
+	history = append(history, stream.events...)
+	if stream.done {
+		close(ch)
+	} else {
+		stream.subscribers[ch] = struct{}{}
+	}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, subscribed := stream.subscribers[ch]; subscribed {
+			delete(stream.subscribers, ch)
+			close(ch)
+		}
+		// Drop streams nobody published to, e.g. for jobs run elsewhere
+		if len(stream.events) == 0 && len(stream.subscribers) == 0 && b.streams[id] == stream {
+			delete(b.streams, id)
+		}
+	}
+	return history, ch, ok && len(stream.events) > 0, cancel
+}
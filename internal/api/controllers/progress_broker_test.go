@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+func TestProgressBrokerCutsOffSlowSubscribers(t *testing.T) {
+	broker := NewProgressBroker()
+	broker.Publish("job", entity.StageUploaded, 0, nil, "")
+
+	_, slow, _, cancelSlow := broker.Subscribe("job")
+	defer cancelSlow()
+	_, fast, _, cancelFast := broker.Subscribe("job")
+	defer cancelFast()
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		// A requeued job goes through its stages again, more than a buffer's worth
+		for i := 0; i < progressBuffer+2; i++ {
+			broker.Publish("job", entity.StageInferring, 0, nil, "")
+			<-fast
+		}
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that does not read")
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != progressBuffer {
+		t.Errorf("slow subscriber got %d events before it was cut off, want %d", received, progressBuffer)
+	}
+
+	// The fast subscriber keeps getting events
+	broker.Publish("job", entity.StageDone, 0, nil, "")
+	if event, ok := <-fast; !ok || event.Stage != entity.StageDone {
+		t.Errorf("fast subscriber got %+v, %v, want the done event", event, ok)
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// parseResultOptions reads top_k, min_average and min_count from the query
+// string or form, keeping the defaults for any that are missing.
+func parseResultOptions(ctx *gin.Context, defaults entity.ResultOptions) (entity.ResultOptions, error) {
+	options := defaults
+
+	if value := ctx.DefaultQuery("top_k", ctx.PostForm("top_k")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return options, errors.New("top_k must be a non-negative integer")
+		}
+		options.TopK = n
+	}
+	if value := ctx.DefaultQuery("min_average", ctx.PostForm("min_average")); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 || f > 1 {
+			return options, errors.New("min_average must be between 0 and 1")
+		}
+		options.MinAverage = f
+	}
+	if value := ctx.DefaultQuery("min_count", ctx.PostForm("min_count")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return options, errors.New("min_count must be a non-negative integer")
+		}
+		options.MinCount = n
+	}
+	return options, nil
+}
+
+// rankClasses drops the classes below their minimum average confidence or
+// frame count and returns the top k of the rest. Classes are ordered by
+// average confidence, highest first; ties are broken by frame count, highest
+// first, and then by class name so the order never changes between requests.
+// A class override takes precedence over options for that class.
+func rankClasses(avgs []entity.ProcessedAvg, options entity.ResultOptions, overrides map[string]entity.ClassThreshold) []entity.ProcessedAvg {
+	ranked := make([]entity.ProcessedAvg, 0, len(avgs))
+	for _, avg := range avgs {
+		minAverage, minCount := options.MinAverage, options.MinCount
+		if override, ok := overrides[avg.Key]; ok {
+			if override.MinAverage != nil {
+				minAverage = *override.MinAverage
+			}
+			if override.MinCount != nil {
+				minCount = *override.MinCount
+			}
+		}
+		if avg.Average < minAverage || avg.Count < minCount {
+			continue
+		}
+		ranked = append(ranked, avg)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Average != ranked[j].Average {
+			return ranked[i].Average > ranked[j].Average
+		}
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Key < ranked[j].Key
+	})
+
+	if options.TopK > 0 && len(ranked) > options.TopK {
+		ranked = ranked[:options.TopK]
+	}
+	return ranked
+}
+
+// processedAvgs lists the per-class averages returned by the ML API.
+func processedAvgs(avg map[string]valueobjects.MlAverage) []entity.ProcessedAvg {
+	processed := make([]entity.ProcessedAvg, 0, len(avg))
+	for key, value := range avg {
+		processed = append(processed, entity.ProcessedAvg{
+			Key:     key,
+			Average: value.Average,
+			Sum:     value.Sum,
+			Count:   value.Count,
+		})
+	}
+	return processed
+}
+
+// topClass returns the class ranked first without any thresholds, or "" if
+// there is none.
+func topClass(avg map[string]valueobjects.MlAverage) string {
+	ranked := rankClasses(processedAvgs(avg), entity.ResultOptions{TopK: 1}, nil)
+	if len(ranked) == 0 {
+		return ""
+	}
+	return ranked[0].Key
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(n int) *int           { return &n }
+
+func rankedKeys(avgs []entity.ProcessedAvg) string {
+	keys := make([]string, len(avgs))
+	for i, avg := range avgs {
+		keys[i] = avg.Key
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestRankClasses(t *testing.T) {
+	avgs := []entity.ProcessedAvg{
+		{Key: "hello", Average: 0.6, Count: 10},
+		{Key: "yes", Average: 0.9, Count: 2},
+		{Key: "no", Average: 0.3, Count: 20},
+		{Key: "please", Average: 0.6, Count: 12},
+		{Key: "sorry", Average: 0.6, Count: 12},
+	}
+
+	tests := []struct {
+		name      string
+		options   entity.ResultOptions
+		overrides map[string]entity.ClassThreshold
+		want      string
+	}{
+		{
+			name: "ordered by average, count, then name",
+			want: "yes,please,sorry,hello,no",
+		},
+		{
+			name:    "top k",
+			options: entity.ResultOptions{TopK: 2},
+			want:    "yes,please",
+		},
+		{
+			name:    "top k larger than the classes",
+			options: entity.ResultOptions{TopK: 10},
+			want:    "yes,please,sorry,hello,no",
+		},
+		{
+			name:    "minimum average",
+			options: entity.ResultOptions{MinAverage: 0.6},
+			want:    "yes,please,sorry,hello",
+		},
+		{
+			name:    "minimum count",
+			options: entity.ResultOptions{MinCount: 11},
+			want:    "please,sorry,no",
+		},
+		{
+			name:    "thresholds before top k",
+			options: entity.ResultOptions{MinCount: 11, TopK: 1},
+			want:    "please",
+		},
+		{
+			name:      "override lowers the minimum for its class",
+			options:   entity.ResultOptions{MinAverage: 0.5},
+			overrides: map[string]entity.ClassThreshold{"no": {Class: "no", MinAverage: floatPtr(0.2)}},
+			want:      "yes,please,sorry,hello,no",
+		},
+		{
+			name:      "override raises the minimum for its class",
+			overrides: map[string]entity.ClassThreshold{"yes": {Class: "yes", MinCount: intPtr(3)}, "hello": {Class: "hello", MinAverage: floatPtr(0.7)}},
+			want:      "please,sorry,no",
+		},
+		{
+			name:      "override keeps the other option",
+			options:   entity.ResultOptions{MinAverage: 0.5, MinCount: 5},
+			overrides: map[string]entity.ClassThreshold{"yes": {Class: "yes", MinCount: intPtr(1)}},
+			want:      "yes,please,sorry,hello",
+		},
+		{
+			name:    "nothing passes",
+			options: entity.ResultOptions{MinAverage: 0.95},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rankedKeys(rankClasses(avgs, tt.options, tt.overrides)); got != tt.want {
+				t.Errorf("rankClasses = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopClass(t *testing.T) {
+	tests := []struct {
+		name string
+		avg  map[string]valueobjects.MlAverage
+		want string
+	}{
+		{"none", nil, ""},
+		{"highest average", map[string]valueobjects.MlAverage{"a": {Average: 0.4, Count: 9}, "b": {Average: 0.8, Count: 1}}, "b"},
+		{"tie broken by count", map[string]valueobjects.MlAverage{"a": {Average: 0.5, Count: 2}, "b": {Average: 0.5, Count: 3}}, "b"},
+		{"tie broken by name", map[string]valueobjects.MlAverage{"b": {Average: 0.5, Count: 2}, "a": {Average: 0.5, Count: 2}}, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topClass(tt.avg); got != tt.want {
+				t.Errorf("topClass = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResultOptions(t *testing.T) {
+	defaults := entity.ResultOptions{TopK: 5, MinAverage: 0.1, MinCount: 1}
+	tests := []struct {
+		name    string
+		query   string
+		want    entity.ResultOptions
+		wantErr bool
+	}{
+		{name: "defaults", want: defaults},
+		{name: "all set", query: "top_k=0&min_average=0.5&min_count=3", want: entity.ResultOptions{TopK: 0, MinAverage: 0.5, MinCount: 3}},
+		{name: "some set", query: "min_count=4", want: entity.ResultOptions{TopK: 5, MinAverage: 0.1, MinCount: 4}},
+		{name: "negative top k", query: "top_k=-1", wantErr: true},
+		{name: "top k not a number", query: "top_k=all", wantErr: true},
+		{name: "average above 1", query: "min_average=1.5", wantErr: true},
+		{name: "negative count", query: "min_count=-2", wantErr: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("GET", "/predict?"+tt.query, nil)
+
+			got, err := parseResultOptions(ctx, defaults)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseResultOptions = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseResultOptions = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// assembleGlosses turns a timeline into the ordered sequence of signs
+// performed in the clip. Segments with a mean confidence below the minimum
+// or fewer frames than the minimum are dropped as transitions, and a sign
+// that continues on both sides of a dropped segment is only counted once.
+func assembleGlosses(timeline []entity.TimelineSegment, options entity.SentenceOptions) []string {
+	var glosses []string
+	for _, segment := range timeline {
+		frames := segment.EndFrame - segment.StartFrame + 1
+		if segment.MeanConfidence < options.MinConfidence || frames < options.MinFrames {
+			continue
+		}
+		if len(glosses) > 0 && glosses[len(glosses)-1] == segment.Class {
+			continue
+		}
+		glosses = append(glosses, segment.Class)
+	}
+	return glosses
+}
+
+// glossText joins glosses into the sentence sent for translation. Class names
+// use underscores between words, e.g. "thank_you".
+func glossText(glosses []string) string {
+	return strings.ReplaceAll(strings.Join(glosses, " "), "_", " ")
+}
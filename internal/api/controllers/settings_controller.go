@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+type SettingsController struct {
+	logger          *zap.Logger
+	settingsRepo    *database.UserSettingsRepository
+	languages       validators.Languages
+	defaultLanguage string
+}
+
+func NewSettingsController(settingsRepo *database.UserSettingsRepository, languages validators.Languages, defaultLanguage string, logger *zap.Logger) *SettingsController {
+	return &SettingsController{
+		logger:          logger,
+		settingsRepo:    settingsRepo,
+		languages:       languages,
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+// @Summary Get my settings
+// @Description Returns the caller's settings, with the server defaults filled in for anything never set
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Success  200 {object} entity.ResponseWrapper{data=entity.UserSettings}
+// @Router /me/settings [get]
+func (c *SettingsController) GetSettings(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	settings, err := c.settingsRepo.Get(sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		settings = &entity.UserSettings{}
+	} else if err != nil {
+		c.logger.Error("Error reading user settings", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading settings"})
+		return
+	}
+	if settings.DefaultLanguage == "" {
+		settings.DefaultLanguage = c.defaultLanguage
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// @Summary Update my settings
+// @Description Changes the settings present in the body and keeps the others. default_language is the translation language used when a prediction request names none, an empty string clears it. training_consent allows the caller's videos and feedback to be used as training data.
+// @Tags api
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   body body entity.UserSettingsUpdate true "Settings to change"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.UserSettings}
+// @Failure  400 {object} map[string]interface{}
+// @Router /me/settings [put]
+func (c *SettingsController) UpdateSettings(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	var update entity.UserSettingsUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if update.DefaultLanguage != nil && *update.DefaultLanguage != "" {
+		language, ok := c.languages.Normalize(*update.DefaultLanguage)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Language is not supported", "code": validators.CodeLanguageUnsupported})
+			return
+		}
+		update.DefaultLanguage = &language
+	}
+
+	if err := c.settingsRepo.Update(sub.(string), &update); err != nil {
+		c.logger.Error("Error saving user settings", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving settings"})
+		return
+	}
+
+	settings, err := c.settingsRepo.Get(sub.(string))
+	if err != nil {
+		c.logger.Error("Error reading user settings", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading settings"})
+		return
+	}
+	if settings.DefaultLanguage == "" {
+		settings.DefaultLanguage = c.defaultLanguage
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": settings})
+}
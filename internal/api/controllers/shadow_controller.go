@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// ShadowController reports how a shadow model compares with the models that
+// serve users.
+type ShadowController struct {
+	logger        *zap.Logger
+	shadowRepo    *database.ShadowPredictionRepository
+	defaultShadow string
+}
+
+// NewShadowController returns a controller reporting on defaultShadow unless
+// a request names another shadow model.
+func NewShadowController(shadowRepo *database.ShadowPredictionRepository, defaultShadow string, logger *zap.Logger) *ShadowController {
+	return &ShadowController{
+		logger:        logger,
+		shadowRepo:    shadowRepo,
+		defaultShadow: defaultShadow,
+	}
+}
+
+// @Summary Shadow model report
+// @Description Summarises how often a shadow model agrees on the top-1 class with the model that served each prediction, and how its confidence differs per class. Admins only.
+// @Tags admin
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   model query string false "Shadow model, defaults to the configured shadow backend"
+// @Param   from query string false "Only comparisons made at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param   to query string false "Only comparisons made before this time (RFC 3339 or YYYY-MM-DD)"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.ShadowReport}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  403 {object} map[string]interface{}
+// @Router /admin/shadow/report [get]
+func (c *ShadowController) GetReport(ctx *gin.Context) {
+	filter := entity.ShadowReportFilter{ShadowModel: ctx.DefaultQuery("model", c.defaultShadow)}
+	if filter.ShadowModel == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "model is required when no shadow backend is configured"})
+		return
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(ctx, "from"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseTimeQuery(ctx, "to"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := c.shadowRepo.Report(filter)
+	if err != nil {
+		c.logger.Error("Error building shadow report", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while building the shadow report"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// ShadowRunner runs a candidate model on videos that were already predicted
+// and stores how its result compares, without the user ever seeing it.
+type ShadowRunner struct {
+	ctx        context.Context
+	logger     *zap.Logger
+	backend    *httpadapter.MLBackend
+	shadowRepo *database.ShadowPredictionRepository
+	cacheRepo  *database.PredictionCacheRepository
+	sampleRate float64
+	// slots bounds how many videos are sent to the shadow backend at a time
+	slots chan struct{}
+}
+
+// NewShadowRunner returns a runner for backend. sampleRate is the share of
+// predictions that are shadowed; shadow calls are abandoned once ctx is
+// cancelled.
+func NewShadowRunner(ctx context.Context, backend *httpadapter.MLBackend, shadowRepo *database.ShadowPredictionRepository, cacheRepo *database.PredictionCacheRepository, sampleRate float64, concurrency int, logger *zap.Logger) *ShadowRunner {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &ShadowRunner{
+		ctx:        ctx,
+		logger:     logger,
+		backend:    backend,
+		shadowRepo: shadowRepo,
+		cacheRepo:  cacheRepo,
+		sampleRate: sampleRate,
+		slots:      make(chan struct{}, concurrency),
+	}
+}
+
+// Run compares prediction with the shadow model's result for the same video
+// in the background. The video is skipped when the shadow model is already
+// busy, so shadow traffic never queues up or slows down real predictions.
+func (r *ShadowRunner) Run(prediction *entity.Prediction, s3Link string) {
+	if prediction.Model == r.backend.Name || rand.Float64() >= r.sampleRate {
+		return
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		r.logger.Debug("Shadow backend busy, skipping prediction", zap.Int64("prediction", prediction.ID))
+		return
+	}
+
+	go func() {
+		defer func() { <-r.slots }()
+		r.compare(prediction.ID, prediction.Model, prediction.ContentHash, prediction.Avg, s3Link)
+	}()
+}
+
+func (r *ShadowRunner) compare(predictionID int64, primaryModel string, contentHash string, primaryAvg map[string]valueobjects.MlAverage, s3Link string) {
+	shadow := &entity.ShadowPrediction{
+		PredictionID: predictionID,
+		PrimaryModel: primaryModel,
+		ShadowModel:  r.backend.Name,
+		PrimaryClass: topClass(primaryAvg),
+		PrimaryAvg:   primaryAvg,
+	}
+
+	started := time.Now()
+	avg, err := r.infer(contentHash, s3Link)
+	shadow.InferenceMs = time.Since(started).Milliseconds()
+	if r.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		r.logger.Warn("Shadow inference failed", zap.String("backend", r.backend.Name), zap.Int64("prediction", predictionID), zap.Error(err))
+		shadow.Error = err.Error()
+	} else {
+		shadow.ShadowAvg = avg
+		shadow.ShadowClass = topClass(avg)
+		shadow.Agree = shadow.PrimaryClass == shadow.ShadowClass
+	}
+
+	if err := r.shadowRepo.Create(shadow); err != nil {
+		r.logger.Error("Failed to record shadow prediction", zap.Int64("prediction", predictionID), zap.Error(err))
+	}
+}
+
+// infer returns the shadow model's per-class averages for a video, reusing
+// and filling the prediction cache like real predictions do.
+func (r *ShadowRunner) infer(contentHash string, s3Link string) (map[string]valueobjects.MlAverage, error) {
+	var result []byte
+	if contentHash != "" {
+		cached, err := r.cacheRepo.Get(contentHash, r.backend.Name)
+		if err == nil {
+			result = cached
+		} else if !errors.Is(err, database.ErrNotFound) {
+			r.logger.Warn("Failed to read prediction cache", zap.Error(err))
+		}
+	}
+
+	fresh := result == nil
+	if fresh {
+		var err error
+		result, err = r.backend.Service.Predict(r.ctx, s3Link)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var response valueobjects.MlResponse
+	if err := json.Unmarshal(result, &response); err != nil {
+		return nil, err
+	}
+	if fresh && contentHash != "" {
+		if err := r.cacheRepo.Put(contentHash, r.backend.Name, result); err != nil {
+			r.logger.Warn("Failed to cache ML result", zap.String("hash", contentHash), zap.Error(err))
+		}
+	}
+	return response.Results.Avg, nil
+}
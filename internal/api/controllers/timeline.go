@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// buildTimeline merges consecutive frames of the same class into segments.
+// Frames without a class break a segment and are left out. fps is the frame
+// rate the frames were sampled at, or zero if unknown.
+func buildTimeline(frames []valueobjects.MlFrame, fps float64) []entity.TimelineSegment {
+	timeline := []entity.TimelineSegment{}
+	var sum float64
+
+	for i, frame := range frames {
+		last := len(timeline) - 1
+		if last >= 0 && timeline[last].Class == frame.Class && timeline[last].EndFrame == i-1 {
+			segment := &timeline[last]
+			segment.EndFrame = i
+			if frame.Conf > segment.PeakConfidence {
+				segment.PeakConfidence = frame.Conf
+			}
+			sum += frame.Conf
+			segment.MeanConfidence = sum / float64(segment.EndFrame-segment.StartFrame+1)
+			continue
+		}
+		if frame.Class == "" {
+			continue
+		}
+
+		sum = frame.Conf
+		timeline = append(timeline, entity.TimelineSegment{
+			Class:          frame.Class,
+			StartFrame:     i,
+			EndFrame:       i,
+			PeakConfidence: frame.Conf,
+			MeanConfidence: frame.Conf,
+		})
+	}
+
+	if fps > 0 {
+		for i := range timeline {
+			start := float64(timeline[i].StartFrame) / fps
+			end := float64(timeline[i].EndFrame+1) / fps
+			timeline[i].StartSeconds = &start
+			timeline[i].EndSeconds = &end
+		}
+	}
+	return timeline
+}
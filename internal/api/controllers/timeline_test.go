@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+func frames(classes string, confidences ...float64) []valueobjects.MlFrame {
+	out := make([]valueobjects.MlFrame, len(classes))
+	for i, c := range classes {
+		if c != '.' {
+			out[i].Class = string(c)
+		}
+		out[i].Conf = confidences[i]
+	}
+	return out
+}
+
+func TestBuildTimeline(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []valueobjects.MlFrame
+		want   []entity.TimelineSegment
+	}{
+		{
+			name: "no frames",
+			want: []entity.TimelineSegment{},
+		},
+		{
+			name:   "one class",
+			frames: frames("aaa", 0.5, 0.9, 0.7),
+			want:   []entity.TimelineSegment{{Class: "a", StartFrame: 0, EndFrame: 2, PeakConfidence: 0.9, MeanConfidence: 0.7}},
+		},
+		{
+			name:   "class changes",
+			frames: frames("aabbba", 0.4, 0.6, 0.9, 0.8, 0.7, 0.3),
+			want: []entity.TimelineSegment{
+				{Class: "a", StartFrame: 0, EndFrame: 1, PeakConfidence: 0.6, MeanConfidence: 0.5},
+				{Class: "b", StartFrame: 2, EndFrame: 4, PeakConfidence: 0.9, MeanConfidence: 0.8},
+				{Class: "a", StartFrame: 5, EndFrame: 5, PeakConfidence: 0.3, MeanConfidence: 0.3},
+			},
+		},
+		{
+			name:   "frames without a class break a segment",
+			frames: frames(".aa..a.", 0.1, 0.2, 0.4, 0.1, 0.1, 0.9, 0.1),
+			want: []entity.TimelineSegment{
+				{Class: "a", StartFrame: 1, EndFrame: 2, PeakConfidence: 0.4, MeanConfidence: 0.3},
+				{Class: "a", StartFrame: 5, EndFrame: 5, PeakConfidence: 0.9, MeanConfidence: 0.9},
+			},
+		},
+		{
+			name:   "only frames without a class",
+			frames: frames("...", 0.1, 0.1, 0.1),
+			want:   []entity.TimelineSegment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildTimeline(tt.frames, 0)
+			if len(got) != len(tt.want) {
+				t.Fatalf("buildTimeline = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Class != w.Class || g.StartFrame != w.StartFrame || g.EndFrame != w.EndFrame ||
+					!approxEqual(g.PeakConfidence, w.PeakConfidence) || !approxEqual(g.MeanConfidence, w.MeanConfidence) {
+					t.Errorf("segment %d = %+v, want %+v", i, g, w)
+				}
+				if g.StartSeconds != nil || g.EndSeconds != nil {
+					t.Errorf("segment %d has times without a frame rate", i)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildTimelineSeconds(t *testing.T) {
+	got := buildTimeline(frames("aab", 0.5, 0.5, 0.5), 10)
+	want := []struct{ start, end float64 }{{0, 0.2}, {0.2, 0.3}}
+	if len(got) != len(want) {
+		t.Fatalf("buildTimeline = %+v, want %d segments", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].StartSeconds == nil || got[i].EndSeconds == nil {
+			t.Fatalf("segment %d has no times", i)
+		}
+		// A segment ends where its last frame ends
+		if !approxEqual(*got[i].StartSeconds, w.start) || !approxEqual(*got[i].EndSeconds, w.end) {
+			t.Errorf("segment %d spans %gs to %gs, want %gs to %gs", i, *got[i].StartSeconds, *got[i].EndSeconds, w.start, w.end)
+		}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
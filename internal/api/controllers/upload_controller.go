@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// uploadURLExpiry is how long a presigned upload URL stays valid.
+const uploadURLExpiry = 15 * time.Minute
+
+var (
+	uploadIDPattern  = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(\.[a-z0-9]{1,5})?$`)
+	extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+)
+
+type UploadController struct {
+	logger    *zap.Logger
+	s3Adapter s3.S3Adapter
+}
+
+func NewUploadController(s3Adapter s3.S3Adapter, logger *zap.Logger) *UploadController {
+	return &UploadController{
+		logger:    logger,
+		s3Adapter: s3Adapter,
+	}
+}
+
+// @Summary Request a direct upload URL
+// @Description Returns a presigned S3 PUT URL under the caller's prefix. Upload the video there, then call POST /predict/{key} with the returned upload_id.
+// @Tags api
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   Idempotency-Key header string false "Unique key of this request; a retry with the same key gets the first response back instead of running again"
+// @Param   body body entity.UploadRequest false "Original file name and content type"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.PresignedUpload}
+// @Failure  400 {object} map[string]interface{}
+// @Failure  409 {object} map[string]interface{}
+// @Router /uploads [post]
+func (c *UploadController) CreateUpload(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	var req entity.UploadRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	id, err := database.NewID()
+	if err != nil {
+		c.logger.Error("Error generating upload ID", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating upload"})
+		return
+	}
+
+	ext := strings.ToLower(path.Ext(req.Filename))
+	if !extensionPattern.MatchString(ext) {
+		ext = ".mp4"
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "video/mp4"
+	}
+
+	uploadID := id + ext
+	key := uploadKey(sub.(string), uploadID)
+	url, err := c.s3Adapter.PresignPutObject(key, contentType, uploadURLExpiry)
+	if err != nil {
+		c.logger.Error("Error presigning upload URL", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating upload"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": entity.PresignedUpload{
+		UploadID:  uploadID,
+		Key:       key,
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: time.Now().UTC().Add(uploadURLExpiry),
+	}})
+}
+
+// uploadKey returns the object key of a direct upload, scoped to the caller.
+func uploadKey(sub string, uploadID string) string {
+	return uploadPrefix(sub) + uploadID
+}
+
+// uploadPrefix returns the prefix holding all direct uploads of a user.
+func uploadPrefix(sub string) string {
+	return "uploads/" + sub + "/"
+}
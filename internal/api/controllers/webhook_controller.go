@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+const (
+	// maxWebhooksPerUser bounds how many webhooks a single user can register.
+	maxWebhooksPerUser = 10
+	// deliveryLogSize is how many deliveries the delivery log returns.
+	deliveryLogSize = 50
+)
+
+type WebhookController struct {
+	logger        *zap.Logger
+	webhookRepo   *database.WebhookRepository
+	deliveryRepo  *database.WebhookDeliveryRepository
+	dispatcher    *WebhookDispatcher
+	allowInsecure bool
+}
+
+func NewWebhookController(webhookRepo *database.WebhookRepository, deliveryRepo *database.WebhookDeliveryRepository, dispatcher *WebhookDispatcher, allowInsecure bool, logger *zap.Logger) *WebhookController {
+	return &WebhookController{
+		logger:        logger,
+		webhookRepo:   webhookRepo,
+		deliveryRepo:  deliveryRepo,
+		dispatcher:    dispatcher,
+		allowInsecure: allowInsecure,
+	}
+}
+
+// @Summary Register a webhook
+// @Description Registers a URL that is called with a JSON payload when one of the caller's predictions succeeds or fails.
+// @Description Each call carries the X-Manu-Event, X-Manu-Delivery, X-Manu-Timestamp and X-Manu-Signature headers. The signature is "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a dot and the raw body, keyed with the secret returned here.
+// @Description Failed calls are retried with exponential backoff.
+// @Tags api
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   body body entity.WebhookRequest true "Webhook URL and events, defaulting to all prediction events"
+// @Success  201 {object} entity.ResponseWrapper{data=entity.Webhook}
+// @Failure  400 {object} map[string]interface{}
+// @Router /webhooks [post]
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	var req entity.WebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.validateURL(req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := webhookEvents(req.Events)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := c.webhookRepo.List(sub.(string))
+	if err != nil {
+		c.logger.Error("Error listing webhooks", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating webhook"})
+		return
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Too many webhooks, delete one first"})
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.logger.Error("Error generating webhook secret", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating webhook"})
+		return
+	}
+
+	webhook := &entity.Webhook{Sub: sub.(string), URL: req.URL, Secret: secret, Events: events}
+	if err := c.webhookRepo.Create(webhook); err != nil {
+		c.logger.Error("Error creating webhook", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating webhook"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": webhook})
+}
+
+// @Summary List my webhooks
+// @Description Lists the caller's webhooks. Secrets are only shown when a webhook is created.
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.Webhook}
+// @Router /webhooks [get]
+func (c *WebhookController) ListWebhooks(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	webhooks, err := c.webhookRepo.List(sub.(string))
+	if err != nil {
+		c.logger.Error("Error listing webhooks", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing webhooks"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": webhooks})
+}
+
+// @Summary Delete a webhook
+// @Description Deletes a webhook and its delivery log. Pending retries are dropped.
+// @Tags api
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path string true "Webhook ID"
+// @Success  204
+// @Failure  404 {object} map[string]interface{}
+// @Router /webhooks/{id} [delete]
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return
+	}
+
+	err := c.webhookRepo.Delete(ctx.Param("id"), sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.logger.Error("Error deleting webhook", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting webhook"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description Returns the latest deliveries of a webhook, newest first, with the outcome of their last attempt
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path string true "Webhook ID"
+// @Success  200 {object} entity.ResponseWrapper{data=[]entity.WebhookDelivery}
+// @Failure  404 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries [get]
+func (c *WebhookController) ListDeliveries(ctx *gin.Context) {
+	webhook, ok := c.ownWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := c.deliveryRepo.ListByWebhook(webhook.ID, deliveryLogSize)
+	if err != nil {
+		c.logger.Error("Error listing webhook deliveries", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing deliveries"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+// @Summary Test a webhook
+// @Description Sends a signed webhook.test event to the webhook right away, without retries, and returns the delivery with its outcome
+// @Tags api
+// @Security BearerAuth
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}" default(Bearer <Add access token here>)
+// @Param   id path string true "Webhook ID"
+// @Success  200 {object} entity.ResponseWrapper{data=entity.WebhookDelivery}
+// @Failure  404 {object} map[string]interface{}
+// @Router /webhooks/{id}/test [post]
+func (c *WebhookController) TestWebhook(ctx *gin.Context) {
+	webhook, ok := c.ownWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	delivery, err := c.dispatcher.Test(ctx.Request.Context(), webhook)
+	if err != nil {
+		c.logger.Error("Error testing webhook", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while testing webhook"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": delivery})
+}
+
+// ownWebhook loads the webhook named in the path if it belongs to the
+// caller. It responds with an error and returns false otherwise.
+func (c *WebhookController) ownWebhook(ctx *gin.Context) (*entity.Webhook, bool) {
+	sub, exists := ctx.Get("sub")
+	if !exists {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+		return nil, false
+	}
+
+	webhook, err := c.webhookRepo.Get(ctx.Param("id"), sub.(string))
+	if errors.Is(err, database.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.logger.Error("Error reading webhook", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading webhook"})
+		return nil, false
+	}
+	return webhook, true
+}
+
+// validateURL only accepts absolute HTTPS URLs, or HTTP ones as well when
+// insecure webhooks are allowed for local development.
+func (c *WebhookController) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("url must be an absolute URL")
+	}
+	if u.Scheme != "https" && !(c.allowInsecure && u.Scheme == "http") {
+		return errors.New("url must use https")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	return nil
+}
+
+// webhookEvents validates the requested events, defaulting to all of them.
+func webhookEvents(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return entity.WebhookEvents, nil
+	}
+
+	var events []string
+	seen := make(map[string]bool)
+	for _, event := range requested {
+		if !isWebhookEvent(event) {
+			return nil, errors.New("unknown event " + event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range entity.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// newWebhookSecret returns a random signing secret.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
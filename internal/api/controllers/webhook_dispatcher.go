@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+const (
+	// webhookPollInterval is how often due retries are looked for when no
+	// new delivery wakes the dispatcher.
+	webhookPollInterval = 5 * time.Second
+	// webhookLease is how long a claimed delivery is reserved for one attempt.
+	webhookLease = time.Minute
+	// webhookMaxBackoff caps the delay between two attempts.
+	webhookMaxBackoff = time.Hour
+	// webhookConcurrency is how many deliveries are sent at the same time.
+	webhookConcurrency = 4
+	webhookBatchSize   = 50
+	// webhookPurgeInterval is how often old deliveries are deleted.
+	webhookPurgeInterval = time.Hour
+)
+
+// WebhookDispatcher records webhook deliveries and sends them in the
+// background, retrying failed ones with exponential backoff. Deliveries are
+// kept in the database, so pending retries survive a restart, and deleted
+// once they are older than the retention.
+type WebhookDispatcher struct {
+	logger       *zap.Logger
+	webhookRepo  *database.WebhookRepository
+	deliveryRepo *database.WebhookDeliveryRepository
+	sender       *httpadapter.WebhookSender
+	maxAttempts  int
+	backoff      time.Duration
+	retention    time.Duration
+	wake         chan struct{}
+}
+
+func NewWebhookDispatcher(webhookRepo *database.WebhookRepository, deliveryRepo *database.WebhookDeliveryRepository, sender *httpadapter.WebhookSender, maxAttempts int, backoff time.Duration, retention time.Duration, logger *zap.Logger) *WebhookDispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &WebhookDispatcher{
+		logger:       logger,
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		sender:       sender,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+		retention:    retention,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify records a delivery of event for every webhook of sub subscribed to
+// it. Failures are logged rather than returned, as they must never fail the
+// operation that raised the event.
+func (d *WebhookDispatcher) Notify(sub string, event string, data interface{}) {
+	webhooks, err := d.webhookRepo.ListForEvent(sub, event)
+	if err != nil {
+		d.logger.Error("Failed to list webhooks", zap.String("event", event), zap.Error(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(entity.WebhookPayload{Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		d.logger.Error("Failed to encode webhook payload", zap.String("event", event), zap.Error(err))
+		return
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &entity.WebhookDelivery{WebhookID: webhook.ID, Event: event, Payload: payload}
+		if err := d.deliveryRepo.Create(delivery); err != nil {
+			d.logger.Error("Failed to record webhook delivery", zap.String("webhook", webhook.ID), zap.Error(err))
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Test sends a test event to a webhook right away, without retrying, and
+// returns the recorded delivery.
+func (d *WebhookDispatcher) Test(ctx context.Context, webhook *entity.Webhook) (*entity.WebhookDelivery, error) {
+	payload, err := json.Marshal(entity.WebhookPayload{
+		Event:     entity.WebhookTest,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]string{"webhook_id": webhook.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored as not due so the background loop leaves it alone
+	later := time.Now().UTC().Add(webhookLease)
+	delivery := &entity.WebhookDelivery{WebhookID: webhook.ID, Event: entity.WebhookTest, Payload: payload, NextAttemptAt: &later}
+	if err := d.deliveryRepo.Create(delivery); err != nil {
+		return nil, err
+	}
+
+	d.attempt(ctx, delivery, webhook, false)
+	return delivery, nil
+}
+
+// Start sends due deliveries and deletes old ones until ctx is cancelled.
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(webhookPurgeInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := d.deliveryRepo.DeleteFinishedBefore(time.Now().Add(-d.retention)); err != nil {
+				d.logger.Warn("Failed to delete old webhook deliveries", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			d.dispatchDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+func (d *WebhookDispatcher) dispatchDue(ctx context.Context) {
+	ids, err := d.deliveryRepo.ListDueIDs(webhookBatchSize)
+	if err != nil {
+		d.logger.Error("Failed to list due webhook deliveries", zap.Error(err))
+		return
+	}
+
+	sem := make(chan struct{}, webhookConcurrency)
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		delivery, webhook, claimed, err := d.deliveryRepo.Claim(id, webhookLease)
+		if err != nil {
+			d.logger.Error("Failed to claim webhook delivery", zap.Int64("delivery", id), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.attempt(ctx, delivery, webhook, true)
+		}()
+	}
+	wg.Wait()
+}
+
+// attempt sends a delivery once and records the outcome. A failed delivery
+// is scheduled again unless retry is false or it ran out of attempts.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *entity.WebhookDelivery, webhook *entity.Webhook, retry bool) {
+	status, err := d.sender.Send(ctx, webhook.URL, webhook.Secret, delivery.Event, strconv.FormatInt(delivery.ID, 10), delivery.Payload)
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.NextAttemptAt = nil
+
+	switch {
+	case err == nil:
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.Error = ""
+	case retry && delivery.Attempts < d.maxAttempts:
+		delivery.Status = entity.WebhookDeliveryPending
+		delivery.Error = err.Error()
+		next := time.Now().UTC().Add(d.backoffFor(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	}
+	if err != nil {
+		d.logger.Info("Webhook delivery failed", zap.Int64("delivery", delivery.ID), zap.Int("attempt", delivery.Attempts), zap.Error(err))
+	}
+
+	if err := d.deliveryRepo.Update(delivery); err != nil {
+		d.logger.Error("Failed to record webhook delivery attempt", zap.Int64("delivery", delivery.ID), zap.Error(err))
+	}
+}
+
+// backoffFor returns the delay after the given number of failed attempts:
+// the base backoff, doubled after each further attempt.
+func (d *WebhookDispatcher) backoffFor(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxBackoff {
+		delay = webhookMaxBackoff
+	}
+	return delay
+}
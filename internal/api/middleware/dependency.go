@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeDependencyUnavailable is the error code returned while a dependency
+// needed by the endpoint is down.
+const CodeDependencyUnavailable = "dependency_unavailable"
+
+// DependencyChecker reports whether a dependency can be used.
+type DependencyChecker interface {
+	Healthy(name string) bool
+	// RetryAfter is how long until a dependency that is down is checked again
+	RetryAfter() time.Duration
+}
+
+// RequireDependency answers 503 with a Retry-After header while dependency
+// is down, without running the rest of the chain.
+func RequireDependency(checker DependencyChecker, dependency string, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker.Healthy(dependency) {
+			c.Next()
+			return
+		}
+		seconds := int(checker.RetryAfter().Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": message, "code": CodeDependencyUnavailable})
+	}
+}
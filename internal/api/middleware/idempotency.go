@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// Headers of idempotent requests.
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// Error codes returned when an idempotent request is not run.
+const (
+	CodeIdempotencyKeyInvalid  = "idempotency_key_invalid"
+	CodeIdempotencyKeyInFlight = "idempotency_key_in_flight"
+	CodeIdempotencyKeyReused   = "idempotency_key_reused"
+)
+
+// maxIdempotencyKeyLength is the longest key that is accepted.
+const maxIdempotencyKeyLength = 255
+
+// idempotencyPurgeInterval is how often expired keys are deleted.
+const idempotencyPurgeInterval = time.Hour
+
+// Idempotency makes requests that carry an Idempotency-Key header safe to
+// retry. The first request with a key runs and its response is stored per
+// user; a retry gets the stored response back, or a 409 while the first one
+// is still running, without running the handler again.
+type Idempotency struct {
+	logger      *zap.Logger
+	repo        *database.IdempotencyRepository
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+func NewIdempotency(repo *database.IdempotencyRepository, ttl time.Duration, lockTimeout time.Duration, logger *zap.Logger) *Idempotency {
+	return &Idempotency{
+		logger:      logger,
+		repo:        repo,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Start deletes expired keys in the background until ctx is cancelled.
+func (i *Idempotency) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(idempotencyPurgeInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := i.repo.DeleteExpired(time.Now().Add(-i.ttl)); err != nil {
+				i.logger.Warn("Failed to delete expired idempotency keys", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Middleware applies the idempotency key of a request. It must run after the
+// authentication middleware, as keys are scoped to the user. Requests
+// without a key are passed through.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must not be longer than 255 characters", "code": CodeIdempotencyKeyInvalid})
+			return
+		}
+		sub := c.GetString("sub")
+		if sub == "" {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Subject not found"})
+			return
+		}
+
+		fingerprint := requestFingerprint(c.Request)
+		record, started, err := i.repo.Begin(sub, key, fingerprint, i.ttl, i.lockTimeout)
+		if errors.Is(err, database.ErrNotFound) {
+			// The first request failed and released the key just now
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress", "code": CodeIdempotencyKeyInFlight})
+			return
+		}
+		if err != nil {
+			i.logger.Error("Error reading idempotency key", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error while checking Idempotency-Key"})
+			return
+		}
+
+		if !started {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request", "code": CodeIdempotencyKeyReused})
+			case record.Status == entity.IdempotencyInFlight:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress", "code": CodeIdempotencyKeyInFlight})
+			default:
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Data(record.ResponseStatus, record.ContentType, record.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Also runs when the handler panics, so the key does not stay
+			// locked until the lock times out
+			if completed {
+				return
+			}
+			if err := i.repo.Release(sub, key); err != nil {
+				i.logger.Warn("Failed to release idempotency key", zap.Error(err))
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are usually transient, so the request may run again
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		if err := i.repo.Complete(sub, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			i.logger.Error("Failed to store idempotent response", zap.Error(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// requestFingerprint identifies what a request does, so a key reused for a
+// different request is caught. The body is left out as it may be a large
+// upload; the multipart boundary changes between retries and is left out too.
+func requestFingerprint(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	hash := sha256.Sum256([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " " + mediaType))
+	return hex.EncodeToString(hash[:])
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// MLBackendHeader names the ML backend a prediction must use, bypassing the
+// weighted routing. Only admins may set it, for debugging a model.
+const MLBackendHeader = "X-ML-Backend"
+
+// mlBackendKey is the context key of the forced ML backend.
+const mlBackendKey = "ml_backend"
+
+// MLBackendOverride honours MLBackendHeader for members of adminGroup. The
+// header is ignored for everyone else. It must run after the
+// authentication middleware.
+func MLBackendOverride(adminGroup string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if name := c.GetHeader(MLBackendHeader); name != "" && IsInGroup(c, adminGroup) {
+			c.Set(mlBackendKey, name)
+		}
+		c.Next()
+	}
+}
+
+// ForcedMLBackend returns the ML backend set by MLBackendOverride, or "".
+func ForcedMLBackend(c *gin.Context) string {
+	return c.GetString(mlBackendKey)
+}
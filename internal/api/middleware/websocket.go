@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebSocketTokenProtocol is the subprotocol name a browser offers in front of
+// its access token, as in new WebSocket(url, ["bearer", token]).
+const WebSocketTokenProtocol = "bearer"
+
+// WebSocketToken lets browsers authenticate WebSocket upgrades, which cannot
+// carry an Authorization header. The token is taken from the
+// Sec-WebSocket-Protocol header rather than the query string so it does not
+// end up in access logs. It must run before the authentication middleware.
+func WebSocketToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := webSocketToken(c.Request.Header.Values("Sec-WebSocket-Protocol")); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
+
+// webSocketToken returns the protocol offered right after the token protocol.
+func webSocketToken(headers []string) string {
+	var protocols []string
+	for _, header := range headers {
+		for _, protocol := range strings.Split(header, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	for i := 0; i+1 < len(protocols); i++ {
+		if protocols[i] == WebSocketTokenProtocol {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
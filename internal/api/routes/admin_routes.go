@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	"github.com/Zeta-Manu/Backend/internal/dataset"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+func InitAdminRoutes(router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, health *controllers.HealthMonitor, cfg config.AppConfig) {
+	thresholdRepo := database.NewClassThresholdRepository(dbAdapter)
+	auditRepo := database.NewAuditRepository(dbAdapter)
+	thresholdController := controllers.NewClassThresholdController(thresholdRepo, auditRepo, logger)
+	exporter := dataset.NewExporter(s3Adapter, database.NewFeedbackRepository(dbAdapter), logger)
+	exportController := controllers.NewDatasetExportController(exporter, auditRepo, logger)
+	healthController := controllers.NewHealthController(health)
+	shadowController := controllers.NewShadowController(database.NewShadowPredictionRepository(dbAdapter), cfg.MLInference.ShadowBackend, logger)
+
+	admin := router.Group("/api/admin", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey), middleware.RequireGroup(cfg.JWT.AdminGroup))
+	{
+		admin.GET("/class-thresholds", thresholdController.ListThresholds)
+		admin.PUT("/class-thresholds/:class", thresholdController.PutThreshold)
+		admin.DELETE("/class-thresholds/:class", thresholdController.DeleteThreshold)
+		admin.POST("/exports", exportController.CreateExport)
+		admin.GET("/health", healthController.GetHealth)
+		admin.GET("/shadow/report", shadowController.GetReport)
+	}
+}
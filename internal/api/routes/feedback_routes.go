@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+func InitFeedbackRoutes(router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, cfg config.AppConfig) {
+	predictionRepo := database.NewPredictionRepository(dbAdapter)
+	feedbackRepo := database.NewFeedbackRepository(dbAdapter)
+	feedbackController := controllers.NewFeedbackController(predictionRepo, feedbackRepo, logger)
+
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	{
+		user.POST("/predictions/:id/feedback", feedbackController.CreateFeedback)
+	}
+
+	admin := router.Group("/api/admin", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey), middleware.RequireGroup(cfg.JWT.AdminGroup))
+	{
+		admin.GET("/feedback", feedbackController.ListFeedback)
+	}
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
@@ -9,15 +11,79 @@ import (
 	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
 	"github.com/Zeta-Manu/Backend/internal/adapters/translator"
 	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
 	"github.com/Zeta-Manu/Backend/internal/config"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
 	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
 )
 
-func InitPredictRoutes(router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, translator translator.TranslateAdapter, mlService httpadapter.MLService, cfg config.AppConfig) {
-	predictController := controllers.NewPredictController(dbAdapter, s3Adapter, translator, mlService, logger)
+// InitPredictRoutes registers the prediction endpoints and starts the
+// background prediction workers, which run until ctx is cancelled.
+func InitPredictRoutes(ctx context.Context, router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, translator translator.TranslateAdapter, mlRouter *httpadapter.MLRouter, webhooks *controllers.WebhookDispatcher, idempotency *middleware.Idempotency, health *controllers.HealthMonitor, cfg config.AppConfig) {
+	jobRepo := database.NewPredictionJobRepository(dbAdapter)
+	cacheRepo := database.NewPredictionCacheRepository(dbAdapter)
+	predictionRepo := database.NewPredictionRepository(dbAdapter)
+	workerPool := controllers.NewPredictionWorkerPool(jobRepo, cfg.Predict.Workers, cfg.Predict.QueueSize, logger)
+	videoLimits := validators.VideoLimits{
+		MaxBytes:     cfg.Video.MaxBytes,
+		MaxDuration:  cfg.Video.MaxDuration,
+		AllowedTypes: cfg.Video.AllowedTypes,
+	}
+	languages := validators.Languages{
+		Supported: cfg.Translate.SupportedLanguages,
+		Max:       cfg.Translate.MaxLanguages,
+	}
+	settingsRepo := database.NewUserSettingsRepository(dbAdapter)
+	resultDefaults := entity.ResultOptions{
+		TopK:       cfg.Result.TopK,
+		MinAverage: cfg.Result.MinAverage,
+		MinCount:   cfg.Result.MinCount,
+	}
+	thresholdRepo := database.NewClassThresholdRepository(dbAdapter)
+	sentenceOptions := entity.SentenceOptions{
+		MinConfidence: cfg.Sentence.MinConfidence,
+		MinFrames:     cfg.Sentence.MinFrames,
+	}
+	batchLimits := controllers.BatchLimits{
+		MaxFiles:    cfg.Predict.BatchMaxFiles,
+		Concurrency: cfg.Predict.BatchConcurrency,
+	}
+	streamLimits := controllers.StreamLimits{
+		WindowFrames:    cfg.Stream.WindowFrames,
+		WindowBytes:     cfg.Stream.WindowBytes,
+		MaxMessageBytes: cfg.Stream.MaxMessageBytes,
+		IdleTimeout:     cfg.Stream.IdleTimeout,
+		MaxDuration:     cfg.Stream.MaxDuration,
+		FrameTypes:      cfg.Stream.FrameTypes,
+		ChunkTypes:      cfg.Stream.ChunkTypes,
+	}
+	mlStream := httpadapter.NewMLStreamService(cfg.MLInference.StreamEndpoint, httpadapter.MLClientOptions{
+		RequestTimeout: cfg.MLInference.StreamTimeout,
+		ConnectTimeout: cfg.MLInference.ConnectTimeout,
+		HealthTimeout:  cfg.MLInference.HealthTimeout,
+		MaxIdleConns:   cfg.MLInference.MaxIdleConns,
+	})
+	var shadow *controllers.ShadowRunner
+	if backend, ok := mlRouter.Backend(cfg.MLInference.ShadowBackend); ok {
+		shadow = controllers.NewShadowRunner(ctx, backend, database.NewShadowPredictionRepository(dbAdapter), cacheRepo, cfg.MLInference.ShadowSampleRate, cfg.MLInference.ShadowConcurrency, logger)
+	}
+	predictController := controllers.NewPredictController(dbAdapter, s3Adapter, translator, mlRouter, jobRepo, cacheRepo, predictionRepo, workerPool, videoLimits, languages, cfg.Translate.DefaultLanguage, settingsRepo, resultDefaults, thresholdRepo, sentenceOptions, batchLimits, mlStream, streamLimits, webhooks, controllers.NewProgressBroker(), shadow, logger)
+	workerPool.Start(ctx, predictController.ProcessJob)
+
+	requireML := middleware.RequireDependency(health, controllers.DependencyML, "The ML service is unavailable, try again later")
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey), middleware.MLBackendOverride(cfg.JWT.AdminGroup))
+	{
+		user.POST("/predict", requireML, idempotency.Middleware(), predictController.Predict)
+		user.POST("/predict/batch", requireML, idempotency.Middleware(), predictController.PredictBatch)
+		user.POST("/predict/:key", requireML, idempotency.Middleware(), predictController.PredictUploaded)
+		user.GET("/predict/jobs/:id", predictController.GetJob)
+		user.GET("/predict/:id/events", predictController.JobEvents)
+	}
 
-	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	// The token middleware has to run before authentication
+	stream := router.Group("/api", middleware.WebSocketToken(), manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
 	{
-		user.POST("/predict", predictController.Predict)
+		stream.GET("/stream", predictController.Stream)
 	}
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+func InitPredictionRoutes(router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, s3Adapter s3.S3Adapter, cfg config.AppConfig) {
+	predictionRepo := database.NewPredictionRepository(dbAdapter)
+	jobRepo := database.NewPredictionJobRepository(dbAdapter)
+	cacheRepo := database.NewPredictionCacheRepository(dbAdapter)
+	linkRepo := database.NewS3LinkRepository(dbAdapter)
+	auditRepo := database.NewAuditRepository(dbAdapter)
+	deliveryRepo := database.NewWebhookDeliveryRepository(dbAdapter)
+	idemRepo := database.NewIdempotencyRepository(dbAdapter)
+	predictionController := controllers.NewPredictionController(s3Adapter, predictionRepo, jobRepo, cacheRepo, linkRepo, auditRepo, deliveryRepo, idemRepo, logger)
+
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	{
+		user.GET("/predictions", predictionController.ListPredictions)
+		user.GET("/predictions/:id", predictionController.GetPrediction)
+		user.DELETE("/predictions/:id", predictionController.DeletePrediction)
+		user.DELETE("/me/videos", predictionController.DeleteAllVideos)
+	}
+}
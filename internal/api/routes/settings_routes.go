@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/validators"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+func InitSettingsRoutes(router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, cfg config.AppConfig) {
+	settingsRepo := database.NewUserSettingsRepository(dbAdapter)
+	languages := validators.Languages{
+		Supported: cfg.Translate.SupportedLanguages,
+		Max:       cfg.Translate.MaxLanguages,
+	}
+	settingsController := controllers.NewSettingsController(settingsRepo, languages, cfg.Translate.DefaultLanguage, logger)
+
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	{
+		user.GET("/me/settings", settingsController.GetSettings)
+		user.PUT("/me/settings", settingsController.UpdateSettings)
+	}
+}
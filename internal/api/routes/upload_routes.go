@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/api/middleware"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+func InitUploadRoutes(router *gin.Engine, logger *zap.Logger, s3Adapter s3.S3Adapter, idempotency *middleware.Idempotency, cfg config.AppConfig) {
+	uploadController := controllers.NewUploadController(s3Adapter, logger)
+
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	{
+		user.POST("/uploads", idempotency.Middleware(), uploadController.CreateUpload)
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	httpadapter "github.com/Zeta-Manu/Backend/internal/adapters/http"
+	"github.com/Zeta-Manu/Backend/internal/api/controllers"
+	"github.com/Zeta-Manu/Backend/internal/config"
+	manu_auth "github.com/Zeta-Manu/manu-auth/pkg/middleware"
+)
+
+// InitWebhookRoutes registers the webhook endpoints and starts the webhook
+// dispatcher, which runs until ctx is cancelled. The dispatcher is returned
+// so other controllers can raise events.
+func InitWebhookRoutes(ctx context.Context, router *gin.Engine, logger *zap.Logger, dbAdapter database.DBAdapter, cfg config.AppConfig) *controllers.WebhookDispatcher {
+	webhookRepo := database.NewWebhookRepository(dbAdapter)
+	deliveryRepo := database.NewWebhookDeliveryRepository(dbAdapter)
+	sender := httpadapter.NewWebhookSender(cfg.Webhook.Timeout, cfg.Webhook.AllowInsecure)
+	dispatcher := controllers.NewWebhookDispatcher(webhookRepo, deliveryRepo, sender, cfg.Webhook.MaxAttempts, cfg.Webhook.Backoff, cfg.Webhook.Retention, logger)
+	dispatcher.Start(ctx)
+	webhookController := controllers.NewWebhookController(webhookRepo, deliveryRepo, dispatcher, cfg.Webhook.AllowInsecure, logger)
+
+	user := router.Group("/api", manu_auth.AuthenticationMiddleware(cfg.JWT.JWTPublicKey))
+	{
+		user.POST("/webhooks", webhookController.CreateWebhook)
+		user.GET("/webhooks", webhookController.ListWebhooks)
+		user.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+		user.GET("/webhooks/:id/deliveries", webhookController.ListDeliveries)
+		user.POST("/webhooks/:id/test", webhookController.TestWebhook)
+	}
+	return dispatcher
+}
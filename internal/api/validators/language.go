@@ -0,0 +1,110 @@
+package validators
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Error codes returned to clients when the requested languages are rejected.
+const (
+	CodeLanguageUnsupported = "language_unsupported"
+	CodeTooManyLanguages    = "too_many_languages"
+)
+
+// Languages configures which translation target languages are accepted.
+type Languages struct {
+	Supported []string
+	Max       int
+}
+
+// Normalize maps a language code or BCP 47 tag to its spelling in the
+// supported list, trying the full tag first and then its base language, so
+// "zh-tw" matches "zh-TW" and "th-TH" matches "th".
+func (l Languages) Normalize(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", false
+	}
+	if supported, ok := l.lookup(code); ok {
+		return supported, true
+	}
+
+	tag, err := language.Parse(code)
+	if err != nil {
+		return "", false
+	}
+	if supported, ok := l.lookup(tag.String()); ok {
+		return supported, true
+	}
+	base, _ := tag.Base()
+	return l.lookup(base.String())
+}
+
+// Parse validates explicitly requested languages. Each value may hold several
+// comma-separated codes. Duplicates are dropped and the order is kept.
+func (l Languages) Parse(values []string) ([]string, error) {
+	var languages []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, code := range strings.Split(value, ",") {
+			if strings.TrimSpace(code) == "" {
+				continue
+			}
+			normalized, ok := l.Normalize(code)
+			if !ok {
+				return nil, &ValidationError{http.StatusBadRequest, CodeLanguageUnsupported, fmt.Sprintf("Language %q is not supported", strings.TrimSpace(code))}
+			}
+			if !seen[normalized] {
+				seen[normalized] = true
+				languages = append(languages, normalized)
+			}
+		}
+	}
+
+	if l.Max > 0 && len(languages) > l.Max {
+		return nil, &ValidationError{http.StatusBadRequest, CodeTooManyLanguages, fmt.Sprintf("At most %d languages can be requested", l.Max)}
+	}
+	return languages, nil
+}
+
+// FromAcceptLanguage returns the most preferred supported language of an
+// Accept-Language header, or an empty string if none is supported.
+// Malformed entries are skipped rather than failing the whole header.
+func (l Languages) FromAcceptLanguage(header string) string {
+	type weighted struct {
+		tag language.Tag
+		q   float32
+	}
+
+	var entries []weighted
+	for _, entry := range strings.Split(header, ",") {
+		tags, q, err := language.ParseAcceptLanguage(entry)
+		if err != nil || len(tags) == 0 || q[0] <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tags[0], q[0]})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	for _, entry := range entries {
+		if supported, ok := l.Normalize(entry.tag.String()); ok {
+			return supported
+		}
+	}
+	return ""
+}
+
+func (l Languages) lookup(code string) (string, bool) {
+	for _, supported := range l.Supported {
+		if strings.EqualFold(supported, code) {
+			return supported, true
+		}
+	}
+	return "", false
+}
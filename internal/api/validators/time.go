@@ -0,0 +1,17 @@
+package validators
+
+import (
+	"errors"
+	"time"
+)
+
+// ParseTime parses an RFC 3339 time or a YYYY-MM-DD date, which is taken as
+// midnight UTC.
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("must be an RFC 3339 time or a YYYY-MM-DD date")
+}
@@ -0,0 +1,197 @@
+package validators
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Error codes returned to clients when a video is rejected.
+const (
+	CodeVideoMissing     = "video_missing"
+	CodeVideoTooLarge    = "video_too_large"
+	CodeVideoUnsupported = "video_unsupported_type"
+	CodeVideoTooLong     = "video_too_long"
+	CodeVideoUnreadable  = "video_unreadable"
+	CodeBatchTooLarge    = "batch_too_large"
+)
+
+// isoBaseMediaTypes are the formats whose duration can be read from the
+// movie header box.
+var isoBaseMediaTypes = []string{"video/mp4", "video/quicktime", "video/3gpp", "video/3gpp2", "video/x-m4v"}
+
+// VideoLimits configures which uploads are accepted.
+type VideoLimits struct {
+	MaxBytes     int64
+	MaxDuration  time.Duration
+	AllowedTypes []string
+}
+
+// VideoInfo describes an accepted video.
+type VideoInfo struct {
+	MIME     string
+	Duration time.Duration
+}
+
+// ValidationError is a client error with the HTTP status and code to report.
+type ValidationError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// TooLarge returns the error reported for videos over the size limit.
+func (l VideoLimits) TooLarge() *ValidationError {
+	return &ValidationError{http.StatusRequestEntityTooLarge, CodeVideoTooLarge, fmt.Sprintf("Video must not be larger than %d bytes", l.MaxBytes)}
+}
+
+// ValidateVideo checks the size, sniffed content type and duration of a
+// video. It only reads the first bytes of the content and the box headers
+// needed to find the duration, so it is cheap even for large or remote files.
+func ValidateVideo(r io.ReaderAt, size int64, limits VideoLimits) (*VideoInfo, error) {
+	if size <= 0 {
+		return nil, &ValidationError{http.StatusBadRequest, CodeVideoMissing, "Video file is empty"}
+	}
+	if limits.MaxBytes > 0 && size > limits.MaxBytes {
+		return nil, limits.TooLarge()
+	}
+
+	mtype, err := mimetype.DetectReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	if !isOneOf(mtype, limits.AllowedTypes) {
+		return nil, &ValidationError{http.StatusUnsupportedMediaType, CodeVideoUnsupported, fmt.Sprintf("Video type %s is not supported", mtype.String())}
+	}
+
+	info := &VideoInfo{MIME: mtype.String()}
+	if !isOneOf(mtype, isoBaseMediaTypes) {
+		// The duration of other containers is not inspected
+		return info, nil
+	}
+
+	info.Duration, err = isoBaseMediaDuration(r, size)
+	if err != nil {
+		return nil, &ValidationError{http.StatusUnprocessableEntity, CodeVideoUnreadable, "Video duration could not be read"}
+	}
+	if limits.MaxDuration > 0 && info.Duration > limits.MaxDuration {
+		return nil, &ValidationError{http.StatusUnprocessableEntity, CodeVideoTooLong, fmt.Sprintf("Video must not be longer than %s", limits.MaxDuration)}
+	}
+	return info, nil
+}
+
+func isOneOf(mtype *mimetype.MIME, types []string) bool {
+	for _, t := range types {
+		if mtype.Is(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// isoBaseMediaDuration reads the duration from the moov/mvhd box of an
+// MP4/QuickTime file.
+func isoBaseMediaDuration(r io.ReaderAt, size int64) (time.Duration, error) {
+	moovOffset, moovSize, err := findBox(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhdOffset, mvhdSize, err := findBox(r, moovOffset, moovOffset+moovSize, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+
+	// version(1) flags(3), then creation and modification times, timescale
+	// and duration; times and duration are 64-bit in version 1
+	header := make([]byte, 32)
+	if mvhdSize < int64(len(header)) {
+		header = header[:mvhdSize]
+	}
+	if err := readFull(r, header, mvhdOffset); err != nil {
+		return 0, err
+	}
+	if len(header) < 20 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	var timescale uint32
+	var duration uint64
+	switch header[0] {
+	case 0:
+		timescale = binary.BigEndian.Uint32(header[12:16])
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	case 1:
+		if len(header) < 32 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		timescale = binary.BigEndian.Uint32(header[20:24])
+		duration = binary.BigEndian.Uint64(header[24:32])
+	default:
+		return 0, fmt.Errorf("unknown mvhd version %d", header[0])
+	}
+	if timescale == 0 {
+		return 0, errors.New("mvhd timescale is zero")
+	}
+
+	seconds := float64(duration) / float64(timescale)
+	if seconds > math.MaxInt64/float64(time.Second) {
+		return 0, fmt.Errorf("implausible duration of %g seconds", seconds)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// findBox scans the boxes between start and end for the given type and
+// returns the offset and size of its payload.
+func findBox(r io.ReaderAt, start int64, end int64, boxType string) (int64, int64, error) {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if err := readFull(r, header[:8], offset); err != nil {
+			return 0, 0, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			// The box extends to the end of its parent
+			boxSize = end - offset
+		case 1:
+			if err := readFull(r, header[8:16], offset+8); err != nil {
+				return 0, 0, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if boxSize < headerSize || offset+boxSize > end {
+			return 0, 0, fmt.Errorf("invalid %q box size %d", string(header[4:8]), boxSize)
+		}
+
+		if string(header[4:8]) == boxType {
+			return offset + headerSize, boxSize - headerSize, nil
+		}
+		offset += boxSize
+	}
+	return 0, 0, fmt.Errorf("%s box not found", boxType)
+}
+
+// readFull fills p from offset, treating an EOF that arrives together with
+// the last byte as success.
+func readFull(r io.ReaderAt, p []byte, offset int64) error {
+	n, err := r.ReadAt(p, offset)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
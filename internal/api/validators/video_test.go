@@ -0,0 +1,214 @@
+package validators
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// box encodes an ISO base media box with a 32-bit size.
+func box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out[0:4], uint32(8+len(body)))
+	copy(out[4:8], boxType)
+	return append(out, body...)
+}
+
+// largeBox encodes a box with a 64-bit size.
+func largeBox(boxType string, payload []byte) []byte {
+	out := make([]byte, 16, 16+len(payload))
+	binary.BigEndian.PutUint32(out[0:4], 1)
+	copy(out[4:8], boxType)
+	binary.BigEndian.PutUint64(out[8:16], uint64(16+len(payload)))
+	return append(out, payload...)
+}
+
+// openBox encodes a box with size 0, which extends to the end of its parent.
+func openBox(boxType string, payload []byte) []byte {
+	out := make([]byte, 8, 8+len(payload))
+	copy(out[4:8], boxType)
+	return append(out, payload...)
+}
+
+// mvhd0 is a version 0 movie header payload.
+func mvhd0(timescale uint32, duration uint32) []byte {
+	p := make([]byte, 100)
+	binary.BigEndian.PutUint32(p[12:16], timescale)
+	binary.BigEndian.PutUint32(p[16:20], duration)
+	return p
+}
+
+// mvhd1 is a version 1 movie header payload.
+func mvhd1(timescale uint32, duration uint64) []byte {
+	p := make([]byte, 112)
+	p[0] = 1
+	binary.BigEndian.PutUint32(p[20:24], timescale)
+	binary.BigEndian.PutUint64(p[24:32], duration)
+	return p
+}
+
+var ftyp = box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+
+func mp4(boxes ...[]byte) []byte {
+	return bytes.Join(append([][]byte{ftyp}, boxes...), nil)
+}
+
+func TestISOBaseMediaDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    []byte
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "version 0",
+			file: mp4(box("moov", box("mvhd", mvhd0(1000, 90500)))),
+			want: 90500 * time.Millisecond,
+		},
+		{
+			name: "version 1",
+			file: mp4(box("moov", box("mvhd", mvhd1(600, 600*3600*30)))),
+			want: 30 * time.Hour,
+		},
+		{
+			name: "moov after media data",
+			file: mp4(box("free"), box("mdat", make([]byte, 1024)), box("moov", box("mvhd", mvhd0(90000, 45000)))),
+			want: 500 * time.Millisecond,
+		},
+		{
+			name: "mvhd after other moov boxes",
+			file: mp4(box("moov", box("udta", make([]byte, 12)), box("mvhd", mvhd0(1, 42)), box("trak"))),
+			want: 42 * time.Second,
+		},
+		{
+			name: "64-bit box sizes",
+			file: mp4(largeBox("mdat", make([]byte, 64)), largeBox("moov", largeBox("mvhd", mvhd0(25, 250)))),
+			want: 10 * time.Second,
+		},
+		{
+			name: "moov extends to the end of the file",
+			file: mp4(box("mdat", make([]byte, 32)), openBox("moov", box("mvhd", mvhd0(1000, 1500)))),
+			want: 1500 * time.Millisecond,
+		},
+		{
+			name:    "no moov",
+			file:    mp4(box("mdat", make([]byte, 32))),
+			wantErr: true,
+		},
+		{
+			name:    "no mvhd",
+			file:    mp4(box("moov", box("trak"))),
+			wantErr: true,
+		},
+		{
+			name:    "zero timescale",
+			file:    mp4(box("moov", box("mvhd", mvhd0(0, 100)))),
+			wantErr: true,
+		},
+		{
+			name:    "unknown version",
+			file:    mp4(box("moov", box("mvhd", append([]byte{2}, mvhd0(1000, 100)[1:]...)))),
+			wantErr: true,
+		},
+		{
+			name:    "truncated mvhd",
+			file:    mp4(box("moov", box("mvhd", mvhd0(1000, 100)[:16]))),
+			wantErr: true,
+		},
+		{
+			name:    "truncated version 1 mvhd",
+			file:    mp4(box("moov", box("mvhd", mvhd1(1000, 100)[:24]))),
+			wantErr: true,
+		},
+		{
+			name:    "box larger than its parent",
+			file:    mp4(box("moov", append(box("mvhd", mvhd0(1000, 100))[:4:4], "mvhd"...))),
+			wantErr: true,
+		},
+		{
+			name:    "box smaller than its header",
+			file:    mp4([]byte{0, 0, 0, 4, 'm', 'o', 'o', 'v'}),
+			wantErr: true,
+		},
+		{
+			name:    "file cut off in a box header",
+			file:    mp4(box("moov", box("mvhd", mvhd0(1000, 100))))[:len(ftyp)+4],
+			wantErr: true,
+		},
+		{
+			name:    "implausible duration",
+			file:    mp4(box("moov", box("mvhd", mvhd1(1, 1<<62)))),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isoBaseMediaDuration(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("isoBaseMediaDuration = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("isoBaseMediaDuration: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isoBaseMediaDuration = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVideo(t *testing.T) {
+	limits := VideoLimits{MaxBytes: 4096, MaxDuration: time.Minute, AllowedTypes: []string{"video/mp4", "video/webm"}}
+	short := mp4(box("moov", box("mvhd", mvhd0(1000, 12000))))
+
+	tests := []struct {
+		name     string
+		file     []byte
+		size     int64
+		status   int
+		code     string
+		duration time.Duration
+	}{
+		{name: "accepted", file: short, duration: 12 * time.Second},
+		{name: "empty", file: nil, status: http.StatusBadRequest, code: CodeVideoMissing},
+		{name: "too large", file: short, size: 4097, status: http.StatusRequestEntityTooLarge, code: CodeVideoTooLarge},
+		{name: "not a video", file: []byte("just some text, not a video at all"), status: http.StatusUnsupportedMediaType, code: CodeVideoUnsupported},
+		{name: "too long", file: mp4(box("moov", box("mvhd", mvhd0(1000, 61000)))), status: http.StatusUnprocessableEntity, code: CodeVideoTooLong},
+		{name: "duration unreadable", file: mp4(box("mdat", make([]byte, 16))), status: http.StatusUnprocessableEntity, code: CodeVideoUnreadable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.file))
+			}
+			info, err := ValidateVideo(bytes.NewReader(tt.file), size, limits)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("ValidateVideo: %v", err)
+				}
+				if info.MIME != "video/mp4" || info.Duration != tt.duration {
+					t.Errorf("ValidateVideo = %+v, want video/mp4 of %s", info, tt.duration)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateVideo error = %v, want a *ValidationError", err)
+			}
+			if verr.Status != tt.status || verr.Code != tt.code {
+				t.Errorf("ValidateVideo error = %d %s, want %d %s", verr.Status, verr.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
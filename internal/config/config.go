@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type DatabaseConfig struct {
@@ -30,15 +33,138 @@ type CognitoConfig struct {
 
 type JWTConfig struct {
 	JWTPublicKey string
+	AdminGroup   string
 }
 
 type SageMakerConfig struct {
 	ENDPOINT string
 	Region   string
+	// EndpointURL overrides the AWS service URL, e.g. to point at cmd/mlstub
+	EndpointURL string
+}
+
+// ML backends that can serve predictions.
+const (
+	MLBackendHTTP      = "http"
+	MLBackendSageMaker = "sagemaker"
+)
+
+// How predictions are spread over the ML backends.
+const (
+	// MLRoutingWeighted picks a backend at random for every prediction
+	MLRoutingWeighted = "weighted"
+	// MLRoutingSticky sends all predictions of a user to the same backend
+	MLRoutingSticky = "sticky"
+)
+
+// MLBackendConfig is one named model predictions can be routed to.
+type MLBackendConfig struct {
+	// Name identifies the model in predictions and the prediction cache
+	Name      string
+	Type      string
+	ENDPOINT  string
+	SageMaker SageMakerConfig
+	// Weight is the backend's share of the traffic relative to the others
+	Weight int
 }
 
 type MLInferenceConfig struct {
-	ENDPOINT string
+	// Backend is MLBackendHTTP or MLBackendSageMaker. Live streams always
+	// use the HTTP StreamEndpoint.
+	Backend        string
+	ENDPOINT       string
+	StreamEndpoint string
+	ModelVersion   string
+	// Backends are the models predictions are routed to, by default a
+	// single one named ModelVersion made of Backend and ENDPOINT
+	Backends       []MLBackendConfig
+	Routing        string
+	Timeout        time.Duration
+	StreamTimeout  time.Duration
+	ConnectTimeout time.Duration
+	HealthTimeout  time.Duration
+	MaxIdleConns   int
+	// Failed inference calls are retried up to RetryAttempts times in
+	// total, waiting from RetryBaseDelay up to RetryMaxDelay in between
+	RetryAttempts  int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerFailures consecutive failures stop calls to the ML service
+	// for BreakerOpenTimeout
+	BreakerFailures    int
+	BreakerOpenTimeout time.Duration
+	// ShadowBackend names a backend that is sent a ShadowSampleRate share of
+	// the predictions in the background, at most ShadowConcurrency at a
+	// time, to compare it with the backend that served them
+	ShadowBackend     string
+	ShadowSampleRate  float64
+	ShadowConcurrency int
+}
+
+type PredictConfig struct {
+	Workers          int
+	QueueSize        int
+	BatchMaxFiles    int
+	BatchConcurrency int
+}
+
+type VideoConfig struct {
+	MaxBytes     int64
+	MaxDuration  time.Duration
+	AllowedTypes []string
+}
+
+type TranslateConfig struct {
+	DefaultLanguage    string
+	SupportedLanguages []string
+	MaxLanguages       int
+}
+
+type ResultConfig struct {
+	TopK       int
+	MinAverage float64
+	MinCount   int
+}
+
+type SentenceConfig struct {
+	MinConfidence float64
+	MinFrames     int
+}
+
+type WebhookConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+	// Retention is how long finished deliveries, and the predictions in
+	// their payloads, are kept
+	Retention time.Duration
+	// AllowInsecure permits plain HTTP and private network webhook URLs,
+	// for local development only
+	AllowInsecure bool
+}
+
+type StreamConfig struct {
+	WindowFrames    int
+	WindowBytes     int
+	MaxMessageBytes int
+	IdleTimeout     time.Duration
+	MaxDuration     time.Duration
+	FrameTypes      []string
+	ChunkTypes      []string
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a key and its stored response are kept
+	TTL time.Duration
+	// LockTimeout is how long a key stays locked by a request that never
+	// finished, e.g. because the process died
+	LockTimeout time.Duration
+}
+
+// HealthConfig controls the background checks of the API's dependencies.
+type HealthConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
 }
 
 // The application configuration
@@ -48,7 +174,17 @@ type AppConfig struct {
 	S3          S3Config
 	Cognito     CognitoConfig
 	JWT         JWTConfig
+	SageMaker   SageMakerConfig
 	MLInference MLInferenceConfig
+	Predict     PredictConfig
+	Video       VideoConfig
+	Translate   TranslateConfig
+	Result      ResultConfig
+	Sentence    SentenceConfig
+	Webhook     WebhookConfig
+	Stream      StreamConfig
+	Idempotency IdempotencyConfig
+	Health      HealthConfig
 }
 
 // initializes and returns the application configuration
@@ -79,10 +215,101 @@ func NewAppConfig() *AppConfig {
 
 	jwtConfig := JWTConfig{
 		JWTPublicKey: os.Getenv("JWT_PUBLIC_KEY"),
+		AdminGroup:   getEnv("ADMIN_GROUP", "admin"),
+	}
+
+	sageMakerConfig := SageMakerConfig{
+		ENDPOINT:    os.Getenv("SAGEMAKER_ENDPOINT"),
+		Region:      getEnv("SAGEMAKER_REGION", os.Getenv("REGION")),
+		EndpointURL: os.Getenv("SAGEMAKER_ENDPOINT_URL"),
 	}
 
 	mlInferenceConfig := MLInferenceConfig{
-		ENDPOINT: os.Getenv("ML_INFERENCE_ENDPOINT"),
+		Backend:        getEnv("ML_BACKEND", MLBackendHTTP),
+		ENDPOINT:       os.Getenv("ML_INFERENCE_ENDPOINT"),
+		StreamEndpoint: getEnv("ML_STREAM_ENDPOINT", os.Getenv("ML_INFERENCE_ENDPOINT")),
+		ModelVersion:   getEnv("ML_MODEL_VERSION", "default"),
+		Timeout:        getEnvDuration("ML_TIMEOUT", 2*time.Minute),
+		StreamTimeout:  getEnvDuration("ML_STREAM_TIMEOUT", 10*time.Second),
+		ConnectTimeout: getEnvDuration("ML_CONNECT_TIMEOUT", 5*time.Second),
+		HealthTimeout:  getEnvDuration("ML_HEALTH_TIMEOUT", 5*time.Second),
+		MaxIdleConns:   getEnvInt("ML_MAX_IDLE_CONNS", 16),
+		RetryAttempts:  getEnvInt("ML_RETRY_ATTEMPTS", 3),
+		RetryBaseDelay: getEnvDuration("ML_RETRY_BASE_DELAY", 500*time.Millisecond),
+		RetryMaxDelay:  getEnvDuration("ML_RETRY_MAX_DELAY", 5*time.Second),
+
+		BreakerFailures:    getEnvInt("ML_BREAKER_FAILURES", 5),
+		BreakerOpenTimeout: getEnvDuration("ML_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+
+		ShadowBackend:     os.Getenv("ML_SHADOW_BACKEND"),
+		ShadowSampleRate:  getEnvFloat("ML_SHADOW_SAMPLE_RATE", 1),
+		ShadowConcurrency: getEnvInt("ML_SHADOW_CONCURRENCY", 2),
+	}
+	mlInferenceConfig.Routing = getEnv("ML_ROUTING", MLRoutingWeighted)
+	mlInferenceConfig.Backends = getMLBackends(MLBackendConfig{
+		Name:      mlInferenceConfig.ModelVersion,
+		Type:      mlInferenceConfig.Backend,
+		ENDPOINT:  mlInferenceConfig.ENDPOINT,
+		SageMaker: sageMakerConfig,
+		Weight:    1,
+	})
+
+	predictConfig := PredictConfig{
+		Workers:          getEnvInt("PREDICT_WORKERS", 4),
+		QueueSize:        getEnvInt("PREDICT_QUEUE_SIZE", 100),
+		BatchMaxFiles:    getEnvInt("PREDICT_BATCH_MAX_FILES", 20),
+		BatchConcurrency: getEnvInt("PREDICT_BATCH_CONCURRENCY", 4),
+	}
+
+	videoConfig := VideoConfig{
+		MaxBytes:     int64(getEnvInt("VIDEO_MAX_BYTES", 200<<20)),
+		MaxDuration:  getEnvDuration("VIDEO_MAX_DURATION", 2*time.Minute),
+		AllowedTypes: getEnvList("VIDEO_ALLOWED_TYPES", []string{"video/mp4", "video/quicktime", "video/3gpp", "video/3gpp2", "video/x-m4v"}),
+	}
+
+	translateConfig := TranslateConfig{
+		DefaultLanguage:    getEnv("TRANSLATE_DEFAULT_LANGUAGE", "th"),
+		SupportedLanguages: getEnvList("TRANSLATE_LANGUAGES", []string{"th", "en", "zh", "zh-TW", "ja", "ko", "vi", "id", "ms", "tl", "hi", "es", "fr", "de", "pt", "ar"}),
+		MaxLanguages:       getEnvInt("TRANSLATE_MAX_LANGUAGES", 5),
+	}
+
+	resultConfig := ResultConfig{
+		TopK:       getEnvInt("RESULT_TOP_K", 5),
+		MinAverage: getEnvFloat("RESULT_MIN_AVERAGE", 0.1),
+		MinCount:   getEnvInt("RESULT_MIN_COUNT", 1),
+	}
+
+	sentenceConfig := SentenceConfig{
+		MinConfidence: getEnvFloat("SENTENCE_MIN_CONFIDENCE", 0.5),
+		MinFrames:     getEnvInt("SENTENCE_MIN_FRAMES", 3),
+	}
+
+	webhookConfig := WebhookConfig{
+		Timeout:       getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:   getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		Backoff:       getEnvDuration("WEBHOOK_BACKOFF", 30*time.Second),
+		Retention:     getEnvDuration("WEBHOOK_DELIVERY_RETENTION", 30*24*time.Hour),
+		AllowInsecure: getEnvBool("WEBHOOK_ALLOW_INSECURE", false),
+	}
+
+	streamConfig := StreamConfig{
+		WindowFrames:    getEnvInt("STREAM_WINDOW_FRAMES", 16),
+		WindowBytes:     getEnvInt("STREAM_WINDOW_BYTES", 256<<10),
+		MaxMessageBytes: getEnvInt("STREAM_MAX_MESSAGE_BYTES", 1<<20),
+		IdleTimeout:     getEnvDuration("STREAM_IDLE_TIMEOUT", 30*time.Second),
+		MaxDuration:     getEnvDuration("STREAM_MAX_DURATION", 10*time.Minute),
+		FrameTypes:      getEnvList("STREAM_FRAME_TYPES", []string{"image/jpeg", "image/png", "image/webp"}),
+		ChunkTypes:      getEnvList("STREAM_CHUNK_TYPES", []string{"video/webm", "video/mp4"}),
+	}
+
+	idempotencyConfig := IdempotencyConfig{
+		TTL:         getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		LockTimeout: getEnvDuration("IDEMPOTENCY_LOCK_TIMEOUT", 10*time.Minute),
+	}
+
+	healthConfig := HealthConfig{
+		Interval: getEnvDuration("HEALTH_CHECK_INTERVAL", 15*time.Second),
+		Timeout:  getEnvDuration("HEALTH_CHECK_TIMEOUT", 5*time.Second),
 	}
 
 	return &AppConfig{
@@ -91,6 +318,125 @@ func NewAppConfig() *AppConfig {
 		S3:          s3Config,
 		Cognito:     cognitoConfig,
 		JWT:         jwtConfig,
+		SageMaker:   sageMakerConfig,
 		MLInference: mlInferenceConfig,
+		Predict:     predictConfig,
+		Video:       videoConfig,
+		Translate:   translateConfig,
+		Result:      resultConfig,
+		Sentence:    sentenceConfig,
+		Webhook:     webhookConfig,
+		Stream:      streamConfig,
+		Idempotency: idempotencyConfig,
+		Health:      healthConfig,
+	}
+}
+
+// getEnv reads an environment variable, falling back to def when it is unset
+func getEnv(key string, def string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when
+// it is unset or malformed
+func getEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvBool reads a boolean environment variable, falling back to def when
+// it is unset or malformed
+func getEnvBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
 	}
+	return value
+}
+
+// getEnvFloat reads a floating point environment variable, falling back to
+// def when it is unset or malformed
+func getEnvFloat(key string, def float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvDuration reads a duration such as "90s" or "2m" from the environment,
+// falling back to def when it is unset or malformed
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvList reads a comma-separated list from the environment, falling back
+// to def when it is unset
+func getEnvList(key string, def []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getMLBackends reads the backends named in ML_BACKENDS, each configured by
+// ML_BACKEND_<NAME>_* variables, e.g. ML_BACKEND_V2_ENDPOINT for backend v2.
+// The type and the SageMaker region and URL fall back to def and the weight
+// to 1. Endpoints have no fallback as each backend is a different model. def
+// is the only backend when ML_BACKENDS is unset.
+func getMLBackends(def MLBackendConfig) []MLBackendConfig {
+	names := getEnvList("ML_BACKENDS", nil)
+	if len(names) == 0 {
+		return []MLBackendConfig{def}
+	}
+
+	backends := make([]MLBackendConfig, len(names))
+	for i, name := range names {
+		prefix := "ML_BACKEND_" + envName(name) + "_"
+		backends[i] = MLBackendConfig{
+			Name:     name,
+			Type:     getEnv(prefix+"TYPE", def.Type),
+			ENDPOINT: os.Getenv(prefix + "ENDPOINT"),
+			SageMaker: SageMakerConfig{
+				ENDPOINT:    os.Getenv(prefix + "SAGEMAKER_ENDPOINT"),
+				Region:      getEnv(prefix+"SAGEMAKER_REGION", def.SageMaker.Region),
+				EndpointURL: getEnv(prefix+"SAGEMAKER_ENDPOINT_URL", def.SageMaker.EndpointURL),
+			},
+			Weight: getEnvInt(prefix+"WEIGHT", 1),
+		}
+	}
+	return backends
+}
+
+// envName turns name into the form used in environment variable names:
+// upper case with anything but letters and digits replaced by underscores.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
 }
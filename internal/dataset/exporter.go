@@ -0,0 +1,145 @@
+// Package dataset builds training set manifests from user feedback.
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Zeta-Manu/Backend/internal/adapters/database"
+	"github.com/Zeta-Manu/Backend/internal/adapters/s3"
+	"github.com/Zeta-Manu/Backend/internal/domain/entity"
+)
+
+// exportPrefix is where dataset exports are written in the bucket.
+const exportPrefix = "exports/"
+
+// datasetColumns is the header of the CSV manifest.
+var datasetColumns = []string{"s3_uri", "video_key", "label", "label_source", "predicted_class", "start_seconds", "end_seconds", "consent", "model", "prediction_id", "feedback_id", "created_at"}
+
+// Exporter turns confirmed and corrected predictions into training set
+// manifests. It is used by the admin API and the export command.
+type Exporter struct {
+	logger       *zap.Logger
+	s3Adapter    s3.S3Adapter
+	feedbackRepo *database.FeedbackRepository
+}
+
+func NewExporter(s3Adapter s3.S3Adapter, feedbackRepo *database.FeedbackRepository, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		logger:       logger,
+		s3Adapter:    s3Adapter,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+// Export writes the examples matching filter to a new version under
+// exports/: manifest.jsonl, manifest.csv and, once both are in place,
+// export.json describing the export.
+func (e *Exporter) Export(ctx context.Context, filter entity.DatasetFilter, createdBy string) (*entity.DatasetExport, error) {
+	examples, err := e.feedbackRepo.TrainingExamples(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range examples {
+		examples[i].S3URI = e.s3Adapter.URI(examples[i].VideoKey)
+	}
+
+	id, err := database.NewID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	// Versions sort by time; the suffix keeps exports in the same second apart
+	version := now.Format("20060102T150405Z") + "-" + id[:8]
+	prefix := exportPrefix + version + "/"
+
+	jsonl, err := datasetJSONL(examples)
+	if err != nil {
+		return nil, err
+	}
+	csvData, err := datasetCSV(examples)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.s3Adapter.Upload(ctx, prefix+"manifest.jsonl", bytes.NewReader(jsonl), "application/x-ndjson"); err != nil {
+		return nil, err
+	}
+	if err := e.s3Adapter.Upload(ctx, prefix+"manifest.csv", bytes.NewReader(csvData), "text/csv"); err != nil {
+		return nil, err
+	}
+
+	export := &entity.DatasetExport{
+		Version:   version,
+		Prefix:    e.s3Adapter.URI(prefix),
+		JSONL:     e.s3Adapter.URI(prefix + "manifest.jsonl"),
+		CSV:       e.s3Adapter.URI(prefix + "manifest.csv"),
+		Examples:  len(examples),
+		Filter:    filter,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	metadata, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := e.s3Adapter.Upload(ctx, prefix+"export.json", bytes.NewReader(metadata), "application/json"); err != nil {
+		return nil, err
+	}
+
+	e.logger.Info("Exported training dataset", zap.String("version", version), zap.Int("examples", len(examples)))
+	return export, nil
+}
+
+func datasetJSONL(examples []entity.TrainingExample) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func datasetCSV(examples []entity.TrainingExample) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(datasetColumns); err != nil {
+		return nil, err
+	}
+	for _, example := range examples {
+		record := []string{
+			example.S3URI,
+			example.VideoKey,
+			example.Label,
+			string(example.LabelSource),
+			example.PredictedClass,
+			formatSeconds(example.StartSeconds),
+			formatSeconds(example.EndSeconds),
+			strconv.FormatBool(example.Consent),
+			example.Model,
+			strconv.FormatInt(example.PredictionID, 10),
+			strconv.FormatInt(example.FeedbackID, 10),
+			example.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// formatSeconds leaves the column empty when the example covers the whole video.
+func formatSeconds(seconds *float64) string {
+	if seconds == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*seconds, 'f', -1, 64)
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// ResultOptions select which predicted classes are returned.
+type ResultOptions struct {
+	// TopK limits the number of classes, zero means no limit
+	TopK       int     `json:"top_k"`
+	MinAverage float64 `json:"min_average"`
+	MinCount   int     `json:"min_count"`
+}
+
+// ClassThreshold overrides the minimum average confidence and frame count of
+// a single class. Unset fields fall back to the request or default options.
+type ClassThreshold struct {
+	Class      string    `json:"class"`
+	MinAverage *float64  `json:"min_average"`
+	MinCount   *int      `json:"min_count"`
+	UpdatedBy  string    `json:"updated_by"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ClassThresholdRequest is the body of an admin threshold update.
+type ClassThresholdRequest struct {
+	MinAverage *float64 `json:"min_average" binding:"omitempty,min=0,max=1"`
+	MinCount   *int     `json:"min_count" binding:"omitempty,min=0"`
+}
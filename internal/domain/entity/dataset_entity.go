@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// LabelSource tells how the label of a training example was obtained.
+type LabelSource string
+
+const (
+	// LabelConfirmed is a predicted class the user rated as right
+	LabelConfirmed LabelSource = "confirmed"
+	// LabelCorrected is a class the user named instead of the predicted one
+	LabelCorrected LabelSource = "corrected"
+)
+
+// DatasetFilter selects the feedback exported as training examples. Only
+// examples of users who consented are exported unless IncludeUnconsented is
+// set.
+type DatasetFilter struct {
+	From               *time.Time    `json:"from,omitempty"`
+	To                 *time.Time    `json:"to,omitempty"`
+	Classes            []string      `json:"classes,omitempty"`
+	Sources            []LabelSource `json:"sources,omitempty"`
+	IncludeUnconsented bool          `json:"include_unconsented"`
+}
+
+// TrainingExample is one labelled video or video segment of an export.
+type TrainingExample struct {
+	S3URI          string      `json:"s3_uri"`
+	VideoKey       string      `json:"video_key"`
+	Label          string      `json:"label"`
+	LabelSource    LabelSource `json:"label_source"`
+	PredictedClass string      `json:"predicted_class,omitempty"`
+	StartSeconds   *float64    `json:"start_seconds,omitempty"`
+	EndSeconds     *float64    `json:"end_seconds,omitempty"`
+	Consent        bool        `json:"consent"`
+	Model          string      `json:"model"`
+	PredictionID   int64       `json:"prediction_id"`
+	FeedbackID     int64       `json:"feedback_id"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// DatasetExport describes a manifest written to the bucket. The same
+// examples are written once as JSON lines and once as CSV.
+type DatasetExport struct {
+	Version   string        `json:"version"`
+	Prefix    string        `json:"prefix"`
+	JSONL     string        `json:"jsonl"`
+	CSV       string        `json:"csv"`
+	Examples  int           `json:"examples"`
+	Filter    DatasetFilter `json:"filter"`
+	CreatedBy string        `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
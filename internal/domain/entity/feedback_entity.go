@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+type FeedbackRating string
+
+const (
+	FeedbackUp   FeedbackRating = "up"
+	FeedbackDown FeedbackRating = "down"
+)
+
+// Feedback is a user's verdict on a prediction, optionally naming the sign
+// that was actually shown in the whole video or in a segment of it. The
+// video key, model and predicted class are copied from the prediction so
+// the correction can be used as a training label on its own.
+type Feedback struct {
+	ID             int64          `json:"id"`
+	PredictionID   int64          `json:"prediction_id"`
+	Sub            string         `json:"-"`
+	VideoKey       string         `json:"video_key"`
+	Model          string         `json:"model"`
+	Rating         FeedbackRating `json:"rating"`
+	PredictedClass string         `json:"predicted_class,omitempty"`
+	CorrectClass   string         `json:"correct_class,omitempty"`
+	StartSeconds   *float64       `json:"start_seconds,omitempty"`
+	EndSeconds     *float64       `json:"end_seconds,omitempty"`
+	Note           string         `json:"note,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// FeedbackRequest is the body of a feedback submission. Leave out the
+// timestamps when the feedback is about the whole video.
+type FeedbackRequest struct {
+	Rating       FeedbackRating `json:"rating" binding:"required,oneof=up down"`
+	CorrectClass string         `json:"correct_class" binding:"max=255"`
+	StartSeconds *float64       `json:"start_seconds" binding:"omitempty,min=0"`
+	EndSeconds   *float64       `json:"end_seconds" binding:"omitempty,min=0"`
+	Note         string         `json:"note" binding:"max=2000"`
+}
+
+// FeedbackFilter selects a page of feedback, oldest first.
+type FeedbackFilter struct {
+	After  int64
+	Rating FeedbackRating
+	Limit  int
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type DependencyStatus string
+
+const (
+	// DependencyUnknown is the status of a dependency before its first check
+	DependencyUnknown DependencyStatus = "unknown"
+	DependencyUp      DependencyStatus = "up"
+	DependencyDown    DependencyStatus = "down"
+)
+
+// DependencyHealth is the outcome of the latest check of a dependency.
+// Since is when the dependency entered its current status.
+type DependencyHealth struct {
+	Name      string           `json:"name"`
+	Status    DependencyStatus `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	LatencyMs int64            `json:"latency_ms"`
+	CheckedAt *time.Time       `json:"checked_at,omitempty"`
+	Since     *time.Time       `json:"since,omitempty"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+type IdempotencyStatus string
+
+const (
+	IdempotencyInFlight  IdempotencyStatus = "in_flight"
+	IdempotencyCompleted IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord is the stored outcome of the first request sent with an
+// Idempotency-Key. Fingerprint identifies the request the key was used for.
+type IdempotencyRecord struct {
+	Sub            string
+	Key            string
+	Fingerprint    string
+	Status         IdempotencyStatus
+	ResponseStatus int
+	ContentType    string
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "encoding/json"
+
 type ProcessedAvg struct {
 	Key     string
 	Average float64
@@ -8,9 +10,74 @@ type ProcessedAvg struct {
 }
 
 type PredictResponse struct {
-	Class      string  `json:"class"`
-	Translated string  `json:"translated"`
-	Average    float64 `json:"average"`
-	Sum        float64 `json:"sum"`
-	Count      int     `json:"count"`
+	Class        string            `json:"class"`
+	Translations map[string]string `json:"translations"`
+	Average      float64           `json:"average"`
+	Sum          float64           `json:"sum"`
+	Count        int               `json:"count"`
+}
+
+// UnmarshalJSON also reads results stored before translations were keyed by
+// language, when every class was only translated to Thai.
+func (r *PredictResponse) UnmarshalJSON(data []byte) error {
+	type plain PredictResponse
+	var v struct {
+		plain
+		Translated *string `json:"translated"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*r = PredictResponse(v.plain)
+	if v.Translated != nil && r.Translations == nil {
+		r.Translations = map[string]string{"th": *v.Translated}
+	}
+	return nil
+}
+
+// TimelineSegment is a run of consecutive frames for which the model
+// predicted the same class. Frames are zero-based and EndFrame is inclusive.
+// Times are only set when the ML API reports the frame rate it sampled at.
+type TimelineSegment struct {
+	Class          string   `json:"class"`
+	StartFrame     int      `json:"start_frame"`
+	EndFrame       int      `json:"end_frame"`
+	StartSeconds   *float64 `json:"start_seconds,omitempty"`
+	EndSeconds     *float64 `json:"end_seconds,omitempty"`
+	PeakConfidence float64  `json:"peak_confidence"`
+	MeanConfidence float64  `json:"mean_confidence"`
+}
+
+// Sentence is the ordered sequence of signs recognised in a clip, joined
+// into one sentence and translated as a whole.
+type Sentence struct {
+	Glosses      []string          `json:"glosses"`
+	Text         string            `json:"text"`
+	Translations map[string]string `json:"translations"`
+}
+
+// SentenceOptions decide which timeline segments count as signs when
+// assembling a sentence. Shorter or less confident segments are treated as
+// transitions between signs.
+type SentenceOptions struct {
+	MinConfidence float64
+	MinFrames     int
+}
+
+// BatchPredictItem is the outcome of one video of a batch prediction. Status
+// is the HTTP status the video would have got on its own; either the
+// prediction, the job or the error is set.
+type BatchPredictItem struct {
+	Index        int               `json:"index"`
+	Filename     string            `json:"filename"`
+	Status       int               `json:"status"`
+	PredictionID int64             `json:"prediction_id,omitempty"`
+	Model        string            `json:"model,omitempty"`
+	Result       []PredictResponse `json:"result,omitempty"`
+	Timeline     []TimelineSegment `json:"timeline,omitempty"`
+	Sentence     *Sentence         `json:"sentence,omitempty"`
+	Job          *PredictionJob    `json:"job,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Code         string            `json:"code,omitempty"`
 }
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"time"
+
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+type PredictionStatus string
+
+const (
+	PredictionSucceeded PredictionStatus = "succeeded"
+	PredictionFailed    PredictionStatus = "failed"
+)
+
+// Prediction is the stored outcome of running one video through the pipeline.
+type Prediction struct {
+	ID          int64                             `json:"id"`
+	Sub         string                            `json:"-"`
+	VideoKey    string                            `json:"video_key"`
+	ContentHash string                            `json:"content_hash,omitempty"`
+	Model       string                            `json:"model"`
+	Status      PredictionStatus                  `json:"status"`
+	Cached      bool                              `json:"cached"`
+	Raw         []valueobjects.MlFrame            `json:"raw,omitempty"`
+	Avg         map[string]valueobjects.MlAverage `json:"avg,omitempty"`
+	Result      []PredictResponse                 `json:"result,omitempty"`
+	Timeline    []TimelineSegment                 `json:"timeline,omitempty"`
+	Sentence    *Sentence                         `json:"sentence,omitempty"`
+	Timings     PredictionTimings                 `json:"timings"`
+	Error       string                            `json:"error,omitempty"`
+	CreatedAt   time.Time                         `json:"created_at"`
+}
+
+// PredictionTimings records how long each pipeline step took, in milliseconds.
+type PredictionTimings struct {
+	UploadMs    int64 `json:"upload_ms,omitempty"`
+	InferenceMs int64 `json:"inference_ms"`
+	TranslateMs int64 `json:"translate_ms"`
+	TotalMs     int64 `json:"total_ms"`
+}
+
+// PredictionFilter selects a page of a user's predictions, newest first.
+type PredictionFilter struct {
+	Sub     string
+	Before  int64
+	From    *time.Time
+	To      *time.Time
+	Classes []string
+	Limit   int
+}
+
+// PredictionDetail is a stored prediction with a temporary link to its video.
+type PredictionDetail struct {
+	Prediction
+	VideoURL          string    `json:"video_url"`
+	VideoURLExpiresAt time.Time `json:"video_url_expires_at"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+type PredictionJobStatus string
+
+const (
+	PredictionJobQueued    PredictionJobStatus = "queued"
+	PredictionJobRunning   PredictionJobStatus = "running"
+	PredictionJobSucceeded PredictionJobStatus = "succeeded"
+	PredictionJobFailed    PredictionJobStatus = "failed"
+)
+
+type PredictionJob struct {
+	ID           string              `json:"id"`
+	Sub          string              `json:"-"`
+	VideoKey     string              `json:"-"`
+	S3Link       string              `json:"-"`
+	ContentHash  string              `json:"-"`
+	Languages    []string            `json:"languages,omitempty"`
+	Options      *ResultOptions      `json:"options,omitempty"`
+	MLBackend    string              `json:"-"`
+	PredictionID int64               `json:"prediction_id,omitempty"`
+	Status       PredictionJobStatus `json:"status"`
+	Result       []PredictResponse   `json:"result,omitempty"`
+	Timeline     []TimelineSegment   `json:"timeline,omitempty"`
+	Sentence     *Sentence           `json:"sentence,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
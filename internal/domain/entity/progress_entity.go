@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+type PredictionStage string
+
+const (
+	StageUploaded    PredictionStage = "uploaded"
+	StageStored      PredictionStage = "stored"
+	StageInferring   PredictionStage = "inferring"
+	StageTranslating PredictionStage = "translating"
+	StageDone        PredictionStage = "done"
+	StageError       PredictionStage = "error"
+)
+
+// ProgressEvent reports that a prediction reached a pipeline stage.
+// ElapsedMs is the time since the first event and StageMs the time spent in
+// the previous stage; for the uploaded stage it is the upload time. The done
+// event carries the same data as the synchronous predict response.
+type ProgressEvent struct {
+	ID        int             `json:"-"`
+	Stage     PredictionStage `json:"stage"`
+	At        time.Time       `json:"at"`
+	ElapsedMs int64           `json:"elapsed_ms"`
+	StageMs   int64           `json:"stage_ms"`
+	Data      interface{}     `json:"data,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Final reports whether no more events follow this one.
+func (e ProgressEvent) Final() bool {
+	return e.Stage == StageDone || e.Stage == StageError
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	valueobjects "github.com/Zeta-Manu/Backend/internal/domain/valueObjects"
+)
+
+// ShadowPrediction compares the result a user got with the result of a
+// candidate model run on the same video. Error is set when the shadow model
+// failed, in which case there is nothing to compare.
+type ShadowPrediction struct {
+	ID           int64
+	PredictionID int64
+	PrimaryModel string
+	ShadowModel  string
+	// PrimaryClass and ShadowClass are the top-1 classes of the two models
+	PrimaryClass string
+	ShadowClass  string
+	Agree        bool
+	PrimaryAvg   map[string]valueobjects.MlAverage
+	ShadowAvg    map[string]valueobjects.MlAverage
+	InferenceMs  int64
+	Error        string
+	CreatedAt    time.Time
+}
+
+// ShadowReportFilter selects the comparisons of one shadow model.
+type ShadowReportFilter struct {
+	ShadowModel string
+	From        *time.Time
+	To          *time.Time
+}
+
+// ShadowReport summarises how a shadow model compares with the models that
+// served the same predictions.
+type ShadowReport struct {
+	ShadowModel   string              `json:"shadow_model"`
+	From          *time.Time          `json:"from,omitempty"`
+	To            *time.Time          `json:"to,omitempty"`
+	Compared      int64               `json:"compared"`
+	Failed        int64               `json:"failed"`
+	Agreed        int64               `json:"agreed"`
+	AgreementRate float64             `json:"agreement_rate"`
+	Classes       []ShadowClassReport `json:"classes"`
+}
+
+// ShadowClassReport compares the two models on one class. Predicted and
+// ShadowPredicted count how often the class was the top-1 of the primary and
+// the shadow model, Agreed how often both had it as top-1. The deltas are
+// the mean difference, and the mean absolute difference, of the shadow minus
+// the primary average confidence over the Seen comparisons in which either
+// model saw the class.
+type ShadowClassReport struct {
+	Class              string  `json:"class"`
+	Predicted          int64   `json:"predicted"`
+	ShadowPredicted    int64   `json:"shadow_predicted"`
+	Agreed             int64   `json:"agreed"`
+	AgreementRate      float64 `json:"agreement_rate"`
+	Seen               int64   `json:"seen"`
+	ConfidenceDelta    float64 `json:"confidence_delta"`
+	AbsConfidenceDelta float64 `json:"abs_confidence_delta"`
+}
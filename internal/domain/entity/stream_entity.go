@@ -0,0 +1,38 @@
+package entity
+
+type StreamMessageType string
+
+const (
+	// Sent by the server
+	StreamReady      StreamMessageType = "ready"
+	StreamRecognized StreamMessageType = "recognized"
+	StreamDropped    StreamMessageType = "dropped"
+	StreamError      StreamMessageType = "error"
+	StreamClosed     StreamMessageType = "closed"
+
+	// Sent by the client
+	StreamFlush StreamMessageType = "flush"
+	StreamEnd   StreamMessageType = "end"
+)
+
+// StreamMode is how binary messages of a stream are interpreted.
+type StreamMode string
+
+const (
+	// StreamFrames streams one encoded image per message
+	StreamFrames StreamMode = "frames"
+	// StreamChunks streams consecutive chunks of a video container
+	StreamChunks StreamMode = "chunks"
+)
+
+// StreamMessage is a JSON text message on a live prediction stream.
+// Recognized messages carry the classes of a window, best first, whenever
+// the best class differs from the one recognised before.
+type StreamMessage struct {
+	Type    StreamMessageType `json:"type"`
+	Session string            `json:"session,omitempty"`
+	Window  int               `json:"window,omitempty"`
+	Classes []PredictResponse `json:"classes,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Code    string            `json:"code,omitempty"`
+}
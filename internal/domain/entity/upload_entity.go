@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+type UploadRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+}
+
+type PresignedUpload struct {
+	UploadID  string            `json:"upload_id"`
+	Key       string            `json:"key"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+// UserSettings are the per-user preferences applied when a request does not
+// specify otherwise.
+type UserSettings struct {
+	DefaultLanguage string `json:"default_language"`
+	// TrainingConsent allows the user's videos and feedback to be exported
+	// as training data
+	TrainingConsent bool `json:"training_consent"`
+}
+
+// UserSettingsUpdate changes the settings that are set and keeps the others.
+type UserSettingsUpdate struct {
+	// DefaultLanguage is cleared by an empty string
+	DefaultLanguage *string `json:"default_language"`
+	TrainingConsent *bool   `json:"training_consent"`
+}
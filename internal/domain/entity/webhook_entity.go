@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook events.
+const (
+	WebhookPredictionSucceeded = "prediction.succeeded"
+	WebhookPredictionFailed    = "prediction.failed"
+	WebhookTest                = "webhook.test"
+)
+
+// WebhookEvents are the events a webhook can subscribe to.
+var WebhookEvents = []string{WebhookPredictionSucceeded, WebhookPredictionFailed}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// Webhook is a URL that is called when events of its owner happen. The
+// secret is only returned when the webhook is created.
+type Webhook struct {
+	ID        string    `json:"id"`
+	Sub       string    `json:"-"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest is the body of a webhook registration. Events default to
+// every prediction event.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      string                `json:"webhook_id"`
+	Event          string                `json:"event"`
+	Payload        json.RawMessage       `json:"payload" swaggertype:"object"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	Error          string                `json:"error,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// WebhookPayload is the JSON body posted to a webhook.
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -2,14 +2,22 @@ package valueobjects
 
 type MlResponse struct {
 	Results struct {
-		Raw []struct {
-			Class string  `json:"class"`
-			Conf  float64 `json:"conf"`
-		} `json:"raw"`
-		Avg map[string]struct {
-			Average float64 `json:"average"`
-			Sum     float64 `json:"sum"`
-			Count   int     `json:"count"`
-		} `json:"avg"`
+		Raw []MlFrame            `json:"raw"`
+		Avg map[string]MlAverage `json:"avg"`
+		// FPS is the rate at which frames were sampled; older models omit it
+		FPS float64 `json:"fps,omitempty"`
 	} `json:"results"`
 }
+
+// MlFrame is the top class the model predicted for a single frame.
+type MlFrame struct {
+	Class string  `json:"class"`
+	Conf  float64 `json:"conf"`
+}
+
+// MlAverage aggregates the confidence of a class over all frames.
+type MlAverage struct {
+	Average float64 `json:"average"`
+	Sum     float64 `json:"sum"`
+	Count   int     `json:"count"`
+}